@@ -23,6 +23,7 @@ import (
 	"github.com/portainer/portainer/api/bolt/tag"
 	"github.com/portainer/portainer/api/bolt/team"
 	"github.com/portainer/portainer/api/bolt/teammembership"
+	"github.com/portainer/portainer/api/bolt/telemetry"
 	"github.com/portainer/portainer/api/bolt/template"
 	"github.com/portainer/portainer/api/bolt/user"
 	"github.com/portainer/portainer/api/bolt/version"
@@ -52,6 +53,7 @@ type Store struct {
 	TagService             *tag.Service
 	TeamMembershipService  *teammembership.Service
 	TeamService            *team.Service
+	TelemetryService       *telemetry.Service
 	TemplateService        *template.Service
 	TunnelServerService    *tunnelserver.Service
 	UserService            *user.Service
@@ -102,6 +104,31 @@ func (store *Store) Close() error {
 	return nil
 }
 
+// Snapshot creates a point-in-time copy of the database inside the specified directory
+// using a single read transaction and returns a new store opened on that copy.
+// Writes applied to the original store after the copy are not visible in the snapshot.
+// The caller is responsible for closing the snapshot and removing the directory.
+func (store *Store) Snapshot(directory string) (*Store, error) {
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path.Join(directory, databaseFileName), 0600)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Store{
+		path:        directory,
+		fileService: store.fileService,
+	}
+
+	err = snapshot.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
 // MigrateData automatically migrate the data based on the DBVersion.
 func (store *Store) MigrateData() error {
 	if !store.checkForDataMigration {
@@ -221,6 +248,12 @@ func (store *Store) initServices() error {
 	}
 	store.TeamService = teamService
 
+	telemetryService, err := telemetry.NewService(store.db)
+	if err != nil {
+		return err
+	}
+	store.TelemetryService = telemetryService
+
 	templateService, err := template.NewService(store.db)
 	if err != nil {
 		return err
@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName       = "telemetry"
+	configurationKey = "CONFIGURATION"
+)
+
+// Service represents a service for managing telemetry data.
+type Service struct {
+	db *bolt.DB
+}
+
+// NewService creates a new instance of a service.
+func NewService(db *bolt.DB) (*Service, error) {
+	err := internal.CreateBucket(db, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		db: db,
+	}, nil
+}
+
+// Configuration retrieve the TelemetryConfiguration object.
+func (service *Service) Configuration() (*portainer.TelemetryConfiguration, error) {
+	var configuration portainer.TelemetryConfiguration
+
+	err := internal.GetObject(service.db, BucketName, []byte(configurationKey), &configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configuration, nil
+}
+
+// UpdateConfiguration persists a TelemetryConfiguration object.
+func (service *Service) UpdateConfiguration(configuration *portainer.TelemetryConfiguration) error {
+	return internal.UpdateObject(service.db, BucketName, []byte(configurationKey), configuration)
+}
+
+// UpdateLastRun persists the state of a telemetry run. The configuration is read and updated
+// inside a single transaction so that the run state fields are always updated together.
+func (service *Service) UpdateLastRun(state portainer.TelemetryRunState) error {
+	return service.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		var configuration portainer.TelemetryConfiguration
+		value := bucket.Get([]byte(configurationKey))
+		if value != nil {
+			err := internal.UnmarshalObject(value, &configuration)
+			if err != nil {
+				return err
+			}
+		}
+
+		configuration.LastRun = state.Time
+		configuration.RunSequence++
+		if state.Sent {
+			configuration.LastSubmission = state.Time
+			configuration.ConsecutiveFailures = 0
+		} else if !state.Throttled {
+			configuration.ConsecutiveFailures++
+		}
+
+		data, err := internal.MarshalObject(configuration)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(configurationKey), data)
+	})
+}
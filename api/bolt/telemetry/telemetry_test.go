@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/portainer/portainer/api"
+)
+
+func TestUpdateLastRun(t *testing.T) {
+	directory, err := ioutil.TempDir("", "telemetry-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	db, err := bolt.Open(path.Join(directory, "portainer.db"), 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	service, err := NewService(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.UpdateConfiguration(&portainer.TelemetryConfiguration{TelemetryID: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := service.UpdateLastRun(portainer.TelemetryRunState{Time: 1000, Sent: false})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	err = service.UpdateLastRun(portainer.TelemetryRunState{Time: 2000, Sent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configuration, err := service.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if configuration.TelemetryID != "id" {
+		t.Errorf("Expected TelemetryID to be preserved, but it was %s instead", configuration.TelemetryID)
+	}
+	if configuration.RunSequence != 21 {
+		t.Errorf("Expected RunSequence to be 21, but it was %d instead", configuration.RunSequence)
+	}
+	if configuration.LastRun != 2000 || configuration.LastSubmission != 2000 {
+		t.Errorf("Expected LastRun and LastSubmission to be 2000, but they were %d and %d", configuration.LastRun, configuration.LastSubmission)
+	}
+	if configuration.ConsecutiveFailures != 0 {
+		t.Errorf("Expected ConsecutiveFailures to be reset, but it was %d instead", configuration.ConsecutiveFailures)
+	}
+}
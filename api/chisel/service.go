@@ -180,12 +180,23 @@ func (service *Service) snapshotEnvironment(endpointID portainer.EndpointID, tun
 
 	endpointURL := endpoint.URL
 	endpoint.URL = fmt.Sprintf("tcp://127.0.0.1:%d", tunnelPort)
-	snapshot, err := service.snapshotter.CreateSnapshot(endpoint)
+	snapshot, snapshotError := service.snapshotter.CreateSnapshot(endpoint)
+	endpoint.URL = endpointURL
+
+	endpoint.SnapshotAttempts++
+	endpoint.LastSnapshotFailed = snapshotError != nil
+	if snapshotError != nil {
+		endpoint.SnapshotFailures++
+	}
+
+	if snapshot != nil {
+		endpoint.Snapshots = []portainer.Snapshot{*snapshot}
+	}
+
+	err = service.endpointService.UpdateEndpoint(endpoint.ID, endpoint)
 	if err != nil {
 		return err
 	}
 
-	endpoint.Snapshots = []portainer.Snapshot{*snapshot}
-	endpoint.URL = endpointURL
-	return service.endpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	return snapshotError
 }
@@ -52,6 +52,9 @@ func (*Service) ParseFlags(version string) (*portainer.CLIFlags, error) {
 		SyncInterval:      kingpin.Flag("sync-interval", "Duration between each synchronization via the external endpoints source").Default(defaultSyncInterval).String(),
 		Snapshot:          kingpin.Flag("snapshot", "Start a background job to create endpoint snapshots").Default(defaultSnapshot).Bool(),
 		SnapshotInterval:  kingpin.Flag("snapshot-interval", "Duration between each endpoint snapshot job").Default(defaultSnapshotInterval).String(),
+		TelemetryURL:      kingpin.Flag("telemetry-url", "URL where anonymous usage data is sent (disabled when empty or when analytics are disabled)").String(),
+		PersistTelemetry:  kingpin.Flag("persist-telemetry", "Store the latest anonymous usage data in the database, whether it is sent or not").Default(defaultPersistTelemetry).Bool(),
+		NoTelemetrySend:   kingpin.Flag("no-telemetry-send", "Compute the anonymous usage data without sending it").Default(defaultNoTelemetrySend).Bool(),
 		AdminPassword:     kingpin.Flag("admin-password", "Hashed admin password").String(),
 		AdminPasswordFile: kingpin.Flag("admin-password-file", "Path to the file containing the password for the admin user").String(),
 		Labels:            pairs(kingpin.Flag("hide-label", "Hide containers with a specific label in the UI").Short('l')),
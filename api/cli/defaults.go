@@ -21,5 +21,7 @@ const (
 	defaultSyncInterval        = "60s"
 	defaultSnapshot            = "true"
 	defaultSnapshotInterval    = "5m"
+	defaultPersistTelemetry    = "false"
+	defaultNoTelemetrySend     = "false"
 	defaultTemplateFile        = "/templates.json"
 )
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"log"
 	"os"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	"github.com/portainer/portainer/api/jwt"
 	"github.com/portainer/portainer/api/ldap"
 	"github.com/portainer/portainer/api/libcompose"
+	"github.com/portainer/portainer/api/telemetry"
 )
 
 func initCLI() *portainer.CLIFlags {
@@ -197,6 +199,66 @@ func loadEndpointSyncSystemSchedule(jobScheduler portainer.JobScheduler, schedul
 	return scheduleService.CreateSchedule(endpointSyncSchedule)
 }
 
+func loadTelemetrySystemSchedule(jobScheduler portainer.JobScheduler, endpointService portainer.EndpointService, registryService portainer.RegistryService, settingsService portainer.SettingsService, teamService portainer.TeamService, teamMembershipService portainer.TeamMembershipService, userService portainer.UserService, stackService portainer.StackService, webhookService portainer.WebhookService, resourceControlService portainer.ResourceControlService, scheduleService portainer.ScheduleService, fileService portainer.FileService, reverseTunnelService portainer.ReverseTunnelService, telemetryService portainer.TelemetryService, snapshotStore func() (*telemetry.StoreSnapshot, error), flags *portainer.CLIFlags) error {
+	sendDisabled := *flags.NoAnalytics || *flags.TelemetryURL == "" || *flags.NoTelemetrySend
+	if sendDisabled && !*flags.PersistTelemetry {
+		return nil
+	}
+
+	telemetrySchedule := &portainer.Schedule{
+		Name:           "system_telemetry",
+		CronExpression: "@every 24h",
+		Recurring:      true,
+		JobType:        portainer.TelemetryJobType,
+		Created:        time.Now().Unix(),
+	}
+
+	telemetryJobContext := telemetry.NewTelemetryJobContext(endpointService, registryService, settingsService, teamService, teamMembershipService, userService, stackService, webhookService, resourceControlService, scheduleService, fileService, reverseTunnelService, telemetryService, *flags.TelemetryURL)
+	telemetryJobContext.AuthenticationDisabled = *flags.NoAuth
+	telemetryJobContext.TunnelServerAddress = *flags.TunnelAddr
+	telemetryJobContext.SendDisabled = sendDisabled
+	telemetryJobContext.PersistLocally = *flags.PersistTelemetry
+	telemetryJobContext.SnapshotStore = snapshotStore
+	telemetryJobRunner := telemetry.NewTelemetryJobRunner(telemetrySchedule, telemetryJobContext)
+
+	return jobScheduler.ScheduleJob(telemetryJobRunner)
+}
+
+// telemetryStoreSnapshot returns a function copying the database inside a temporary directory of the
+// data directory, so that the telemetry is computed against a point-in-time copy of the store.
+// The copy is removed once the snapshot is closed.
+func telemetryStoreSnapshot(store *bolt.Store, dataStorePath string) func() (*telemetry.StoreSnapshot, error) {
+	return func() (*telemetry.StoreSnapshot, error) {
+		directory, err := ioutil.TempDir(dataStorePath, "telemetry-snapshot")
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot, err := store.Snapshot(directory)
+		if err != nil {
+			os.RemoveAll(directory)
+			return nil, err
+		}
+
+		return &telemetry.StoreSnapshot{
+			EndpointService:        snapshot.EndpointService,
+			RegistryService:        snapshot.RegistryService,
+			SettingsService:        snapshot.SettingsService,
+			TeamService:            snapshot.TeamService,
+			TeamMembershipService:  snapshot.TeamMembershipService,
+			UserService:            snapshot.UserService,
+			StackService:           snapshot.StackService,
+			WebhookService:         snapshot.WebhookService,
+			ResourceControlService: snapshot.ResourceControlService,
+			ScheduleService:        snapshot.ScheduleService,
+			Close: func() error {
+				defer os.RemoveAll(directory)
+				return snapshot.Close()
+			},
+		}, nil
+	}
+}
+
 func loadSchedulesFromDatabase(jobScheduler portainer.JobScheduler, jobService portainer.JobService, scheduleService portainer.ScheduleService, endpointService portainer.EndpointService, fileService portainer.FileService, reverseTunnelService portainer.ReverseTunnelService) error {
 	schedules, err := scheduleService.Schedules()
 	if err != nil {
@@ -590,6 +652,11 @@ func main() {
 		}
 	}
 
+	err = loadTelemetrySystemSchedule(jobScheduler, store.EndpointService, store.RegistryService, store.SettingsService, store.TeamService, store.TeamMembershipService, store.UserService, store.StackService, store.WebhookService, store.ResourceControlService, store.ScheduleService, fileService, reverseTunnelService, store.TelemetryService, telemetryStoreSnapshot(store, *flags.Data), flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	jobScheduler.Start()
 
 	err = initDockerHub(store.DockerHubService)
@@ -66,9 +66,12 @@ func (runner *SnapshotJobRunner) Run() {
 			}
 
 			latestEndpointReference.Status = portainer.EndpointStatusUp
+			latestEndpointReference.SnapshotAttempts++
+			latestEndpointReference.LastSnapshotFailed = snapshotError != nil
 			if snapshotError != nil {
 				log.Printf("background schedule error (endpoint snapshot). Unable to create snapshot (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, snapshotError)
 				latestEndpointReference.Status = portainer.EndpointStatusDown
+				latestEndpointReference.SnapshotFailures++
 			}
 
 			if snapshot != nil {
@@ -33,6 +33,7 @@ type endpointCreatePayload struct {
 	AzureTenantID          string
 	AzureAuthenticationKey string
 	TagIDs                 []portainer.TagID
+	CreationSource         portainer.EndpointCreationSource
 }
 
 func (payload *endpointCreatePayload) Validate(r *http.Request) error {
@@ -64,6 +65,13 @@ func (payload *endpointCreatePayload) Validate(r *http.Request) error {
 		payload.TagIDs = make([]portainer.TagID, 0)
 	}
 
+	// The Portainer UI identifies itself, every other client is considered as an API client
+	creationSource, _ := request.RetrieveNumericMultiPartFormValue(r, "CreationSource", true)
+	payload.CreationSource = portainer.EndpointCreatedViaAPI
+	if portainer.EndpointCreationSource(creationSource) == portainer.EndpointCreatedViaUI {
+		payload.CreationSource = portainer.EndpointCreatedViaUI
+	}
+
 	useTLS, _ := request.RetrieveBooleanMultiPartFormValue(r, "TLS", true)
 	payload.TLS = useTLS
 
@@ -189,6 +197,7 @@ func (handler *Handler) createAzureEndpoint(payload *endpointCreatePayload) (*po
 		AzureCredentials:   credentials,
 		TagIDs:             payload.TagIDs,
 		Status:             portainer.EndpointStatusUp,
+		CreationSource:     payload.CreationSource,
 		Snapshots:          []portainer.Snapshot{},
 	}
 
@@ -234,6 +243,7 @@ func (handler *Handler) createEdgeAgentEndpoint(payload *endpointCreatePayload)
 		Extensions:      []portainer.EndpointExtension{},
 		TagIDs:          payload.TagIDs,
 		Status:          portainer.EndpointStatusUp,
+		CreationSource:  payload.CreationSource,
 		Snapshots:       []portainer.Snapshot{},
 		EdgeKey:         edgeKey,
 	}
@@ -280,6 +290,7 @@ func (handler *Handler) createUnsecuredEndpoint(payload *endpointCreatePayload)
 		Extensions:         []portainer.EndpointExtension{},
 		TagIDs:             payload.TagIDs,
 		Status:             portainer.EndpointStatusUp,
+		CreationSource:     payload.CreationSource,
 		Snapshots:          []portainer.Snapshot{},
 	}
 
@@ -324,6 +335,7 @@ func (handler *Handler) createTLSSecuredEndpoint(payload *endpointCreatePayload)
 		Extensions:         []portainer.EndpointExtension{},
 		TagIDs:             payload.TagIDs,
 		Status:             portainer.EndpointStatusUp,
+		CreationSource:     payload.CreationSource,
 		Snapshots:          []portainer.Snapshot{},
 	}
 
@@ -35,8 +35,11 @@ func (handler *Handler) endpointSnapshot(w http.ResponseWriter, r *http.Request)
 	}
 
 	latestEndpointReference.Status = portainer.EndpointStatusUp
+	latestEndpointReference.SnapshotAttempts++
+	latestEndpointReference.LastSnapshotFailed = snapshotError != nil
 	if snapshotError != nil {
 		latestEndpointReference.Status = portainer.EndpointStatusDown
+		latestEndpointReference.SnapshotFailures++
 	}
 
 	if snapshot != nil {
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	httperror "github.com/portainer/libhttp/error"
@@ -24,6 +25,7 @@ type settingsUpdatePayload struct {
 	SnapshotInterval                   *string
 	TemplatesURL                       *string
 	EdgeAgentCheckinInterval           *int
+	TelemetryExcludeResourceDetails    *bool
 }
 
 func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
@@ -120,11 +122,17 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 		settings.EdgeAgentCheckinInterval = *payload.EdgeAgentCheckinInterval
 	}
 
+	if payload.TelemetryExcludeResourceDetails != nil {
+		settings.TelemetryExcludeResourceDetails = *payload.TelemetryExcludeResourceDetails
+	}
+
 	tlsError := handler.updateTLS(settings)
 	if tlsError != nil {
 		return tlsError
 	}
 
+	settings.LastModified = time.Now().Unix()
+
 	err = handler.SettingsService.UpdateSettings(settings)
 	if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist settings changes inside the database", err}
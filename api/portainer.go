@@ -1,6 +1,9 @@
 package portainer
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type (
 	// AccessPolicy represent a policy that can be associated to a user or team
@@ -60,6 +63,9 @@ type (
 		SyncInterval      *string
 		Snapshot          *bool
 		SnapshotInterval  *string
+		TelemetryURL      *string
+		PersistTelemetry  *bool
+		NoTelemetrySend   *bool
 	}
 
 	// CLIService represents a service for managing CLI
@@ -96,22 +102,26 @@ type (
 	// Endpoint represents a Docker endpoint with all the info required
 	// to connect to it
 	Endpoint struct {
-		ID                 EndpointID          `json:"Id"`
-		Name               string              `json:"Name"`
-		Type               EndpointType        `json:"Type"`
-		URL                string              `json:"URL"`
-		GroupID            EndpointGroupID     `json:"GroupId"`
-		PublicURL          string              `json:"PublicURL"`
-		TLSConfig          TLSConfiguration    `json:"TLSConfig"`
-		Extensions         []EndpointExtension `json:"Extensions"`
-		AzureCredentials   AzureCredentials    `json:"AzureCredentials,omitempty"`
-		TagIDs             []TagID             `json:"TagIds"`
-		Status             EndpointStatus      `json:"Status"`
-		Snapshots          []Snapshot          `json:"Snapshots"`
-		UserAccessPolicies UserAccessPolicies  `json:"UserAccessPolicies"`
-		TeamAccessPolicies TeamAccessPolicies  `json:"TeamAccessPolicies"`
-		EdgeID             string              `json:"EdgeID,omitempty"`
-		EdgeKey            string              `json:"EdgeKey"`
+		ID                 EndpointID             `json:"Id"`
+		Name               string                 `json:"Name"`
+		Type               EndpointType           `json:"Type"`
+		URL                string                 `json:"URL"`
+		GroupID            EndpointGroupID        `json:"GroupId"`
+		PublicURL          string                 `json:"PublicURL"`
+		TLSConfig          TLSConfiguration       `json:"TLSConfig"`
+		Extensions         []EndpointExtension    `json:"Extensions"`
+		AzureCredentials   AzureCredentials       `json:"AzureCredentials,omitempty"`
+		TagIDs             []TagID                `json:"TagIds"`
+		Status             EndpointStatus         `json:"Status"`
+		Snapshots          []Snapshot             `json:"Snapshots"`
+		UserAccessPolicies UserAccessPolicies     `json:"UserAccessPolicies"`
+		TeamAccessPolicies TeamAccessPolicies     `json:"TeamAccessPolicies"`
+		EdgeID             string                 `json:"EdgeID,omitempty"`
+		EdgeKey            string                 `json:"EdgeKey"`
+		CreationSource     EndpointCreationSource `json:"CreationSource,omitempty"`
+		SnapshotAttempts   int                    `json:"SnapshotAttempts,omitempty"`
+		SnapshotFailures   int                    `json:"SnapshotFailures,omitempty"`
+		LastSnapshotFailed bool                   `json:"LastSnapshotFailed,omitempty"`
 		// Deprecated fields
 		// Deprecated in DBVersion == 4
 		TLS           bool   `json:"TLS,omitempty"`
@@ -161,6 +171,9 @@ type (
 		Tags []string `json:"Tags"`
 	}
 
+	// EndpointCreationSource represents the client used to create an endpoint
+	EndpointCreationSource int
+
 	// EndpointGroupID represents an endpoint group identifier
 	EndpointGroupID int
 
@@ -387,6 +400,8 @@ type (
 		TemplatesURL                       string               `json:"TemplatesURL"`
 		EnableHostManagementFeatures       bool                 `json:"EnableHostManagementFeatures"`
 		EdgeAgentCheckinInterval           int                  `json:"EdgeAgentCheckinInterval"`
+		TelemetryExcludeResourceDetails    bool                 `json:"TelemetryExcludeResourceDetails"`
+		LastModified                       int64                `json:"LastModified"`
 
 		// Deprecated fields
 		DisplayDonationHeader       bool
@@ -435,6 +450,7 @@ type (
 		Env             []Pair           `json:"Env"`
 		ResourceControl *ResourceControl `json:"ResourceControl"`
 		ProjectPath     string
+		Created         int64 `json:"Created"`
 	}
 
 	// StackID represents a stack identifier (it must be composed of Name + "_" + SwarmID to create a unique identifier)
@@ -526,6 +542,26 @@ type (
 		Hostname      string           `json:"hostname,omitempty"`
 	}
 
+	// TelemetryConfiguration represents the data persisted by the telemetry job
+	TelemetryConfiguration struct {
+		TelemetryID         string            `json:"TelemetryID"`
+		IdentifierSource    string            `json:"IdentifierSource"`
+		PendingBatch        []json.RawMessage `json:"PendingBatch"`
+		LastRun             int64             `json:"LastRun"`
+		LastSubmission      int64             `json:"LastSubmission"`
+		RunSequence         int               `json:"RunSequence"`
+		ConsecutiveFailures int               `json:"ConsecutiveFailures"`
+		LocalPayload        json.RawMessage   `json:"LocalPayload,omitempty"`
+		AggregationWindow   []json.RawMessage `json:"AggregationWindow,omitempty"`
+	}
+
+	// TelemetryRunState represents the outcome of a telemetry run to persist
+	TelemetryRunState struct {
+		Time      int64
+		Sent      bool
+		Throttled bool
+	}
+
 	// TemplateEnv represents a template environment variable configuration
 	TemplateEnv struct {
 		Name        string              `json:"name"`
@@ -881,6 +917,13 @@ type (
 		DeleteTeamMembershipByTeamID(teamID TeamID) error
 	}
 
+	// TelemetryService represents a service for managing telemetry data
+	TelemetryService interface {
+		Configuration() (*TelemetryConfiguration, error)
+		UpdateConfiguration(configuration *TelemetryConfiguration) error
+		UpdateLastRun(state TelemetryRunState) error
+	}
+
 	// TemplateService represents a service for managing template data
 	TemplateService interface {
 		Templates() ([]Template, error)
@@ -976,6 +1019,14 @@ const (
 	StoridgeEndpointExtension
 )
 
+const (
+	_ EndpointCreationSource = iota
+	// EndpointCreatedViaAPI represents an endpoint created by an API client
+	EndpointCreatedViaAPI
+	// EndpointCreatedViaUI represents an endpoint created from the Portainer UI
+	EndpointCreatedViaUI
+)
+
 const (
 	_ EndpointStatus = iota
 	// EndpointStatusUp is used to represent an available endpoint
@@ -1016,6 +1067,8 @@ const (
 	// EndpointSyncJobType is a system job used to synchronize endpoints from
 	// an external definition store
 	EndpointSyncJobType
+	// TelemetryJobType is a system job used to send anonymous usage data
+	TelemetryJobType
 )
 
 const (
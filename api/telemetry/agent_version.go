@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// defaultMinimumAgentVersion is the default version below which an agent is reported as outdated
+	defaultMinimumAgentVersion = "1.5.1"
+
+	agentImageRepository = "portainer/agent"
+)
+
+const (
+	agentVersionUnknown = iota
+	agentVersionUpToDate
+	agentVersionOutdated
+)
+
+// snapshotContainerImage represents the image of a container stored inside a snapshot
+type snapshotContainerImage struct {
+	Image string `json:"Image"`
+}
+
+// agentVersionStatus compares the version of the agent of an endpoint to the minimum version.
+// The agent version is the tag of the portainer/agent container found in the latest snapshot,
+// the status is unknown when there is no such container or when its tag is not a semantic
+// version (e.g. latest).
+func agentVersionStatus(endpoint *portainer.Endpoint, minimumVersion string) int {
+	minimum, err := semver.NewVersion(minimumVersion)
+	if err != nil {
+		return agentVersionUnknown
+	}
+
+	version := snapshotAgentVersion(endpoint)
+	if version == nil {
+		return agentVersionUnknown
+	}
+
+	if version.LessThan(*minimum) {
+		return agentVersionOutdated
+	}
+	return agentVersionUpToDate
+}
+
+// snapshotAgentVersion returns the version of the agent container of the latest snapshot of an
+// endpoint, or nil when it cannot be determined.
+func snapshotAgentVersion(endpoint *portainer.Endpoint) *semver.Version {
+	if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].SnapshotRaw.Containers == nil {
+		return nil
+	}
+
+	var containers []snapshotContainerImage
+	err := decodeSnapshotRaw(endpoint.Snapshots[0].SnapshotRaw.Containers, &containers)
+	if err != nil {
+		return nil
+	}
+
+	for _, container := range containers {
+		image := container.Image
+		if index := strings.Index(image, "@"); index != -1 {
+			image = image[:index]
+		}
+
+		index := strings.LastIndex(image, ":")
+		if index == -1 || !strings.HasSuffix(image[:index], agentImageRepository) {
+			continue
+		}
+
+		version, err := semver.NewVersion(strings.TrimPrefix(image[index+1:], "v"))
+		if err != nil {
+			return nil
+		}
+		return version
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func agentEndpoint(id portainer.EndpointID, images ...string) portainer.Endpoint {
+	containers := make([]interface{}, 0)
+	for _, image := range images {
+		containers = append(containers, map[string]interface{}{"Image": image})
+	}
+
+	return portainer.Endpoint{
+		ID:        id,
+		Type:      portainer.AgentOnDockerEnvironment,
+		Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Containers: containers}}},
+	}
+}
+
+func TestAgentVersionStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint portainer.Endpoint
+		expected int
+	}{
+		{"up to date", agentEndpoint(1, "nginx:1.17", "portainer/agent:1.6.0"), agentVersionUpToDate},
+		{"minimum version", agentEndpoint(1, "portainer/agent:1.5.1"), agentVersionUpToDate},
+		{"outdated", agentEndpoint(1, "portainer/agent:1.4.0"), agentVersionOutdated},
+		{"outdated with registry", agentEndpoint(1, "registry.example.com:5000/portainer/agent:v1.2.1"), agentVersionOutdated},
+		{"unparseable tag", agentEndpoint(1, "portainer/agent:latest"), agentVersionUnknown},
+		{"no agent container", agentEndpoint(1, "nginx:1.17"), agentVersionUnknown},
+		{"no snapshot", portainer.Endpoint{ID: 1, Type: portainer.AgentOnDockerEnvironment}, agentVersionUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if status := agentVersionStatus(&c.endpoint, defaultMinimumAgentVersion); status != c.expected {
+				t.Errorf("Expected agent version status %d, but got %d", c.expected, status)
+			}
+		})
+	}
+}
+
+func TestComputeEndpointAgentVersionTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		agentEndpoint(1, "portainer/agent:1.6.0"),
+		agentEndpoint(2, "portainer/agent:1.4.0"),
+		agentEndpoint(3, "portainer/agent:latest"),
+		{ID: 4, Type: portainer.DockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.OutdatedAgentCount != 1 {
+		t.Errorf("Expected OutdatedAgentCount to be 1, but it was %d instead", data.Endpoint.OutdatedAgentCount)
+	}
+	if data.Endpoint.UnknownAgentVersionCount != 1 {
+		t.Errorf("Expected UnknownAgentVersionCount to be 1, but it was %d instead", data.Endpoint.UnknownAgentVersionCount)
+	}
+}
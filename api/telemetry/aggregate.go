@@ -0,0 +1,120 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+
+	"github.com/portainer/portainer/api"
+)
+
+// aggregateWindow appends the telemetry data to the aggregation window persisted in the database,
+// keeping the last AggregationWindow entries, and returns the average of the entries of the window.
+func (context *TelemetryJobContext) aggregateWindow(data *TelemetryData) (*TelemetryData, error) {
+	configuration, err := context.telemetryService.Configuration()
+	if err == portainer.ErrObjectNotFound {
+		configuration = &portainer.TelemetryConfiguration{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	entry, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	window := append(configuration.AggregationWindow, entry)
+	if len(window) > context.AggregationWindow {
+		window = window[len(window)-context.AggregationWindow:]
+	}
+	configuration.AggregationWindow = window
+
+	err = context.telemetryService.UpdateConfiguration(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*TelemetryData, 0, len(window))
+	for _, raw := range window {
+		var windowData TelemetryData
+		err := json.Unmarshal(raw, &windowData)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &windowData)
+	}
+
+	return averageTelemetry(entries), nil
+}
+
+// averageTelemetry returns the average of the telemetry data of a window, ordered from the oldest
+// to the latest entry. The numeric fields of the sections are averaged, integers being rounded to the
+// nearest value. A negative integer (data not tracked) in any entry yields -1, while a negative float
+// (e.g. a rate not tracked) is skipped and yields -1 only when it is negative in every entry.
+// Non-numeric fields (strings, booleans, maps and slices) are taken from the latest entry.
+// WindowSize is set to the number of entries.
+func averageTelemetry(entries []*TelemetryData) *TelemetryData {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	latest := *entries[len(entries)-1]
+	average := &latest
+
+	values := make([]reflect.Value, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, reflect.ValueOf(entry).Elem())
+	}
+	averageValue(reflect.ValueOf(average).Elem(), values)
+
+	average.WindowSize = len(entries)
+	return average
+}
+
+func averageValue(target reflect.Value, values []reflect.Value) {
+	switch target.Kind() {
+	case reflect.Struct:
+		for i := 0; i < target.NumField(); i++ {
+			if target.Type().Field(i).PkgPath != "" {
+				continue
+			}
+
+			fields := make([]reflect.Value, 0, len(values))
+			for _, value := range values {
+				fields = append(fields, value.Field(i))
+			}
+			averageValue(target.Field(i), fields)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var sum int64
+		for _, value := range values {
+			if value.Int() < 0 {
+				target.SetInt(-1)
+				return
+			}
+			sum += value.Int()
+		}
+		target.SetInt(int64(math.Round(float64(sum) / float64(len(values)))))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var sum float64
+		for _, value := range values {
+			sum += float64(value.Uint())
+		}
+		target.SetUint(uint64(math.Round(sum / float64(len(values)))))
+	case reflect.Float32, reflect.Float64:
+		var sum float64
+		var tracked int
+		for _, value := range values {
+			if value.Float() < 0 {
+				continue
+			}
+			sum += value.Float()
+			tracked++
+		}
+		if tracked == 0 {
+			target.SetFloat(-1)
+			return
+		}
+		target.SetFloat(sum / float64(tracked))
+	}
+}
@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestAverageTelemetry(t *testing.T) {
+	entries := []*TelemetryData{
+		{
+			TelemetryID: "id",
+			Endpoint:    EndpointTelemetryData{Count: 2, AgentPercent: 50},
+			Runtime:     RuntimeTelemetryData{Version: "1.23.0"},
+			Settings:    SettingsTelemetryData{HostManagementUsedEndpoints: 1},
+		},
+		{
+			TelemetryID: "id",
+			Endpoint:    EndpointTelemetryData{Count: 3, AgentPercent: 0},
+			Runtime:     RuntimeTelemetryData{Version: "1.23.0"},
+			Settings:    SettingsTelemetryData{HostManagementUsedEndpoints: hostManagementUsageNotTracked},
+		},
+		{
+			TelemetryID: "id",
+			Endpoint:    EndpointTelemetryData{Count: 5, AgentPercent: 100},
+			Runtime:     RuntimeTelemetryData{Version: "1.24.0"},
+			Settings:    SettingsTelemetryData{HostManagementUsedEndpoints: 3},
+		},
+	}
+
+	average := averageTelemetry(entries)
+
+	if average.WindowSize != 3 {
+		t.Errorf("Expected WindowSize to be 3, but it was %d instead", average.WindowSize)
+	}
+	if average.Endpoint.Count != 3 {
+		t.Errorf("Expected the endpoint count to be averaged to 3, but it was %d instead", average.Endpoint.Count)
+	}
+	if average.Endpoint.AgentPercent != 50 {
+		t.Errorf("Expected the agent percentage to be averaged to 50, but it was %f instead", average.Endpoint.AgentPercent)
+	}
+	if average.Settings.HostManagementUsedEndpoints != -1 {
+		t.Errorf("Expected an untracked value to be reported as -1, but it was %d instead", average.Settings.HostManagementUsedEndpoints)
+	}
+	if average.Runtime.Version != "1.24.0" || average.TelemetryID != "id" {
+		t.Errorf("Expected non-numeric fields to be taken from the latest entry, but got %+v", average.Runtime)
+	}
+	if entries[2].Endpoint.Count != 5 {
+		t.Errorf("Expected the entries not to be modified")
+	}
+}
+
+func TestAverageTelemetryUntrackedRate(t *testing.T) {
+	entries := []*TelemetryData{
+		{Endpoint: EndpointTelemetryData{AverageSnapshotSuccessRate: snapshotSuccessRateNotTracked}},
+		{Endpoint: EndpointTelemetryData{AverageSnapshotSuccessRate: 1}},
+		{Endpoint: EndpointTelemetryData{AverageSnapshotSuccessRate: 0.5}},
+	}
+
+	average := averageTelemetry(entries)
+	if average.Endpoint.AverageSnapshotSuccessRate != 0.75 {
+		t.Errorf("Expected the untracked rate to be skipped and the rate to be averaged to 0.75, but it was %f instead", average.Endpoint.AverageSnapshotSuccessRate)
+	}
+
+	entries = []*TelemetryData{
+		{Endpoint: EndpointTelemetryData{AverageSnapshotSuccessRate: snapshotSuccessRateNotTracked}},
+		{Endpoint: EndpointTelemetryData{AverageSnapshotSuccessRate: snapshotSuccessRateNotTracked}},
+	}
+
+	average = averageTelemetry(entries)
+	if average.Endpoint.AverageSnapshotSuccessRate != snapshotSuccessRateNotTracked {
+		t.Errorf("Expected a rate untracked in every entry to be reported as -1, but it was %f instead", average.Endpoint.AverageSnapshotSuccessRate)
+	}
+}
+
+func TestAggregateWindow(t *testing.T) {
+	telemetryService := &fakeTelemetryService{configuration: &portainer.TelemetryConfiguration{}}
+
+	context := newTestTelemetryJobContext()
+	context.telemetryService = telemetryService
+	context.AggregationWindow = 2
+
+	var average *TelemetryData
+	for _, count := range []int{1, 3, 7} {
+		var err error
+		average, err = context.aggregateWindow(&TelemetryData{Stack: StackTelemetryData{Count: count}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(telemetryService.configuration.AggregationWindow) != 2 {
+		t.Errorf("Expected the window to keep 2 entries, but it kept %d", len(telemetryService.configuration.AggregationWindow))
+	}
+	if average.Stack.Count != 5 || average.WindowSize != 2 {
+		t.Errorf("Expected the average of the last 2 entries, but got a count of %d over %d entries", average.Stack.Count, average.WindowSize)
+	}
+}
@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"encoding/json"
+
+	"github.com/portainer/portainer/api"
+)
+
+// defaultMaxPendingBatch is the default maximum number of entries of the batch buffered in the database
+const defaultMaxPendingBatch = 90
+
+// batchTelemetry appends the telemetry data to the batch persisted in the database and
+// sends the whole batch once it is full. The batch is kept when the send fails so that
+// it can be sent during the next run, as well as when the send is throttled.
+// It returns true when the batch was sent.
+func (context *TelemetryJobContext) batchTelemetry(data *TelemetryData, result *TelemetrySendResult) (bool, error) {
+	configuration, err := context.telemetryService.Configuration()
+	if err == portainer.ErrObjectNotFound {
+		configuration = &portainer.TelemetryConfiguration{}
+	} else if err != nil {
+		return false, err
+	}
+
+	entry, err := marshalSchemaVersion(data, context.SchemaVersion)
+	if err != nil {
+		return false, err
+	}
+	configuration.PendingBatch = append(configuration.PendingBatch, entry)
+	configuration.PendingBatch = context.trimPendingBatch(configuration.PendingBatch)
+
+	sent := false
+	var sendErr error
+	if len(configuration.PendingBatch) >= context.BatchSize {
+		if context.sendThrottled(configuration) {
+			context.logf("[INFO] [telemetry] [message: telemetry batch send throttled, last submission is too recent] [min_send_interval: %s]\n", context.MinSendInterval)
+			result.Throttled = true
+		} else {
+			sendErr = context.sendTelemetry(configuration.PendingBatch, result)
+			if sendErr == nil {
+				configuration.PendingBatch = nil
+				sent = true
+			}
+		}
+	}
+
+	err = context.telemetryService.UpdateConfiguration(configuration)
+	if err != nil {
+		return sent, err
+	}
+
+	return sent, sendErr
+}
+
+// trimPendingBatch drops the oldest entries of the batch beyond MaxPendingBatch entries
+func (context *TelemetryJobContext) trimPendingBatch(batch []json.RawMessage) []json.RawMessage {
+	limit := context.MaxPendingBatch
+	if limit <= 0 {
+		return batch
+	}
+	if limit < context.BatchSize {
+		limit = context.BatchSize
+	}
+
+	if len(batch) <= limit {
+		return batch
+	}
+
+	dropped := len(batch) - limit
+	context.logf("[WARN] [telemetry] [message: pending telemetry batch is full, dropping the oldest entries] [dropped: %d] [max_pending_batch: %d]\n", dropped, limit)
+	return append([]json.RawMessage(nil), batch[dropped:]...)
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchTelemetry(t *testing.T) {
+	var requests [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.BatchSize = 3
+	runner := NewTelemetryJobRunner(nil, context)
+
+	for i := 0; i < 3; i++ {
+		result := runner.RunWithResult()
+		if result.Sent != (i == 2) {
+			t.Errorf("Expected run %d to report Sent=%t, but it was %t instead", i, i == 2, result.Sent)
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("Expected a single request to be sent, but %d were sent instead", len(requests))
+	}
+
+	var envelope struct {
+		Data []TelemetryData `json:"data"`
+	}
+	err := json.Unmarshal(requests[0], &envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := envelope.Data
+
+	if len(batch) != 3 {
+		t.Errorf("Expected the batch to contain 3 entries, but it contained %d instead", len(batch))
+	}
+
+	configuration, err := context.telemetryService.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configuration.PendingBatch) != 0 {
+		t.Errorf("Expected the persisted batch to be flushed, but it contained %d entries", len(configuration.PendingBatch))
+	}
+}
+
+func TestBatchTelemetryMaxPendingBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.BatchSize = 2
+	context.MaxPendingBatch = 3
+	context.MinSendInterval = 0
+	runner := NewTelemetryJobRunner(nil, context)
+
+	for i := 0; i < 5; i++ {
+		context.DeploymentLabel = fmt.Sprintf("run-%d", i)
+		runner.RunWithResult()
+	}
+
+	configuration, err := context.telemetryService.Configuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configuration.PendingBatch) != 3 {
+		t.Fatalf("Expected the pending batch to be capped to 3 entries, but it contained %d", len(configuration.PendingBatch))
+	}
+
+	var oldest TelemetryData
+	err = json.Unmarshal(configuration.PendingBatch[0], &oldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldest.DeploymentLabel != "run-2" {
+		t.Errorf("Expected the oldest entries to be dropped, but the oldest entry was %s", oldest.DeploymentLabel)
+	}
+}
@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"hash/fnv"
+)
+
+// destinationURL returns the URL the telemetry data must be sent to: the canary URL when the
+// installation is routed to the canary, the telemetry URL otherwise. The telemetry URL is used
+// when the identifier of the installation cannot be retrieved.
+func (context *TelemetryJobContext) destinationURL() string {
+	if context.CanaryURL == "" || context.CanaryPercent <= 0 {
+		return context.telemetryURL
+	}
+
+	configuration, err := context.telemetryService.Configuration()
+	if err != nil || configuration.TelemetryID == "" {
+		return context.telemetryURL
+	}
+
+	if routedToCanary(configuration.TelemetryID, context.CanaryPercent) {
+		return context.CanaryURL
+	}
+	return context.telemetryURL
+}
+
+// routedToCanary returns true when the installation identified by identifier falls into the
+// percent of installations reporting to the canary. The routing only depends on a hash of the
+// identifier so that an installation keeps reporting to the same URL across runs.
+func routedToCanary(identifier string, percent int) bool {
+	hash := fnv.New32a()
+	hash.Write([]byte(identifier))
+	return int(hash.Sum32()%100) < percent
+}
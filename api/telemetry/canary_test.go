@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestRoutedToCanary(t *testing.T) {
+	identifier := "5b2f0b0e-7c6a-4f4e-9d0e-0e9c1e4b7d0a"
+
+	expected := routedToCanary(identifier, 50)
+	for i := 0; i < 10; i++ {
+		if routedToCanary(identifier, 50) != expected {
+			t.Fatal("Expected the routing to be stable for a given identifier")
+		}
+	}
+
+	if routedToCanary(identifier, 0) {
+		t.Error("Expected no installation to be routed to the canary with a percentage of 0")
+	}
+	if !routedToCanary(identifier, 100) {
+		t.Error("Expected every installation to be routed to the canary with a percentage of 100")
+	}
+
+	routed := 0
+	for i := 0; i < 1000; i++ {
+		if routedToCanary(fmt.Sprintf("installation-%d", i), 10) {
+			routed++
+		}
+	}
+	if routed < 50 || routed > 150 {
+		t.Errorf("Expected about 10%% of the installations to be routed to the canary, but got %d out of 1000", routed)
+	}
+}
+
+func TestDestinationURL(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = "https://telemetry.example.com"
+	context.telemetryService = &fakeTelemetryService{configuration: &portainer.TelemetryConfiguration{TelemetryID: "id"}}
+
+	if url := context.destinationURL(); url != context.telemetryURL {
+		t.Errorf("Expected the telemetry URL to be used when the canary is disabled, but got %s", url)
+	}
+
+	context.CanaryURL = "https://canary.telemetry.example.com"
+	context.CanaryPercent = 100
+	if url := context.destinationURL(); url != context.CanaryURL {
+		t.Errorf("Expected the canary URL to be used, but got %s", url)
+	}
+
+	context.telemetryService = &fakeTelemetryService{}
+	if url := context.destinationURL(); url != context.telemetryURL {
+		t.Errorf("Expected the telemetry URL to be used without identifier, but got %s", url)
+	}
+}
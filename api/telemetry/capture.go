@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"sync"
+)
+
+// payloadCapture holds the payload of the most recent send. It is shared by the copies
+// of the context so that the payload can be read while a run is in progress.
+type payloadCapture struct {
+	mu      sync.Mutex
+	payload []byte
+}
+
+func (capture *payloadCapture) set(payload []byte) {
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	capture.payload = append([]byte(nil), payload...)
+}
+
+func (capture *payloadCapture) get() []byte {
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	return append([]byte(nil), capture.payload...)
+}
+
+// LastPayload returns the exact bytes sent during the most recent send, after compression.
+// It returns nil when CapturePayload is disabled or when nothing was sent yet.
+func (context *TelemetryJobContext) LastPayload() []byte {
+	payload := context.lastPayload.get()
+	if len(payload) == 0 {
+		return nil
+	}
+	return payload
+}
+
+// Resend sends a payload returned by LastPayload to the telemetry URL as is, without computing
+// the telemetry data again, e.g. to reproduce the exact submission of a previous run.
+// The payload is expected to be encoded with the configured format and compression.
+func (runner *TelemetryJobRunner) Resend(payload []byte) error {
+	contentType := "application/json"
+	if runner.context.Format == PayloadFormatProtobuf {
+		contentType = protobufContentType
+	}
+
+	err := runner.context.postPayload(payload, contentType)
+	if err != nil {
+		runner.context.logf("[ERROR] [telemetry] [message: unable to resend telemetry payload] [err: %s]\n", err)
+		return err
+	}
+
+	runner.context.logf("[INFO] [telemetry] [message: telemetry payload resent] [bytes: %d]\n", len(payload))
+	return nil
+}
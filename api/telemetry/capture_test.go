@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLastPayload(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+
+	err := context.sendTelemetry(sampleTelemetryData(), &TelemetrySendResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if context.LastPayload() != nil {
+		t.Errorf("Expected no payload to be captured when the capture is disabled")
+	}
+
+	context.CapturePayload = true
+	context.CompressPayload = true
+
+	err = context.sendTelemetry(sampleTelemetryData(), &TelemetrySendResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(context.LastPayload(), body) {
+		t.Errorf("Expected LastPayload to return the bytes sent")
+	}
+}
+
+func TestResend(t *testing.T) {
+	var body []byte
+	var contentEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		contentEncoding = r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.CompressPayload = true
+	runner := NewTelemetryJobRunner(nil, context)
+
+	payload, err := compressPayload([]byte(`{"data":{"TelemetryID":"id"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runner.Resend(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(body, payload) {
+		t.Errorf("Expected the payload to be received verbatim, but got %v", body)
+	}
+	if contentEncoding != "gzip" {
+		t.Errorf("Expected the Content-Encoding to be gzip, but it was %q instead", contentEncoding)
+	}
+}
+
+func TestResendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	runner := NewTelemetryJobRunner(nil, context)
+
+	err := runner.Resend([]byte("{}"))
+	if err != errInvalidResponseStatus {
+		t.Errorf("Expected the response status error, but got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// CloudProviderAWS represents an endpoint running on Amazon Web Services
+	CloudProviderAWS = "aws"
+	// CloudProviderAzure represents an endpoint running on Microsoft Azure
+	CloudProviderAzure = "azure"
+	// CloudProviderGCP represents an endpoint running on Google Cloud Platform
+	CloudProviderGCP = "gcp"
+	// CloudProviderOnPrem represents an endpoint with no cloud provider signal
+	CloudProviderOnPrem = "onprem"
+	// CloudProviderUnknown represents an endpoint for which no snapshot information is available
+	CloudProviderUnknown = "unknown"
+)
+
+// cloudProviderSignals lists the tokens of the kernel version, operating system and labels
+// (e.g. "amzn2" in "4.14.173-137.229.amzn2.x86_64") and the host name suffixes identifying
+// each cloud provider. Tokens are matched as a whole so that a word merely containing a
+// pattern (e.g. "paws") is not taken for a signal.
+var cloudProviderSignals = []struct {
+	provider     string
+	tokens       []string
+	hostSuffixes []string
+}{
+	{CloudProviderAWS, []string{"amzn", "amzn1", "amzn2", "aws", "amazon"}, []string{".ec2.internal", ".compute.internal"}},
+	{CloudProviderAzure, []string{"azure"}, []string{".cloudapp.net", ".cloudapp.azure.com"}},
+	{CloudProviderGCP, []string{"gcp", "gke", "google"}, nil},
+}
+
+// detectCloudProvider returns a best-effort guess of the cloud provider an endpoint runs on,
+// based on the kernel version, operating system, labels and host name reported by the engine.
+// These values are only matched against known patterns and are never sent.
+func detectCloudProvider(endpoint *portainer.Endpoint) string {
+	if endpoint.Type == portainer.AzureEnvironment {
+		return CloudProviderAzure
+	}
+
+	if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].SnapshotRaw.Info == nil {
+		return CloudProviderUnknown
+	}
+
+	var info snapshotEngineInfo
+	err := decodeSnapshotRaw(endpoint.Snapshots[0].SnapshotRaw.Info, &info)
+	if err != nil {
+		return CloudProviderUnknown
+	}
+
+	tokens := make(map[string]bool)
+	for _, value := range append([]string{info.KernelVersion, info.OperatingSystem}, info.Labels...) {
+		for _, token := range strings.FieldsFunc(strings.ToLower(value), isCloudSignalSeparator) {
+			tokens[token] = true
+		}
+	}
+	hostname := strings.ToLower(info.Name)
+
+	for _, signal := range cloudProviderSignals {
+		for _, token := range signal.tokens {
+			if tokens[token] {
+				return signal.provider
+			}
+		}
+		for _, suffix := range signal.hostSuffixes {
+			if strings.HasSuffix(hostname, suffix) {
+				return signal.provider
+			}
+		}
+	}
+
+	if isGCEHostname(hostname) {
+		return CloudProviderGCP
+	}
+
+	return CloudProviderOnPrem
+}
+
+// isCloudSignalSeparator returns true for the characters separating the tokens of a signal
+func isCloudSignalSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isGCEHostname returns true for the internal host names of the GCE instances,
+// <instance>.c.<project>.internal or <instance>.<zone>.c.<project>.internal
+func isGCEHostname(hostname string) bool {
+	labels := strings.Split(hostname, ".")
+	return len(labels) >= 4 && labels[len(labels)-1] == "internal" && labels[len(labels)-3] == "c"
+}
@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func endpointWithEngineInfo(info map[string]interface{}) portainer.Endpoint {
+	return portainer.Endpoint{
+		Type:      portainer.DockerEnvironment,
+		Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Info: info}}},
+	}
+}
+
+func TestDetectCloudProvider(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint portainer.Endpoint
+		expected string
+	}{
+		{"AWS kernel", endpointWithEngineInfo(map[string]interface{}{"KernelVersion": "4.14.173-137.229.amzn2.x86_64", "Name": "ip-10-0-1-12"}), CloudProviderAWS},
+		{"GCP kernel", endpointWithEngineInfo(map[string]interface{}{"KernelVersion": "5.4.0-1040-gcp", "Name": "docker-host"}), CloudProviderGCP},
+		{"AWS host name", endpointWithEngineInfo(map[string]interface{}{"Name": "ip-10-0-1-12.eu-west-1.compute.internal"}), CloudProviderAWS},
+		{"GCE host name", endpointWithEngineInfo(map[string]interface{}{"Name": "docker-host.c.my-project.internal"}), CloudProviderGCP},
+		{"Zonal GCE host name", endpointWithEngineInfo(map[string]interface{}{"Name": "docker-host.europe-west1-b.c.my-project.internal"}), CloudProviderGCP},
+		{"Host name containing a pattern", endpointWithEngineInfo(map[string]interface{}{"Name": "googlebot-paws-gcebuild", "KernelVersion": "5.4.0-42-generic"}), CloudProviderOnPrem},
+		{"Internal host name", endpointWithEngineInfo(map[string]interface{}{"Name": "docker.corp.internal"}), CloudProviderOnPrem},
+		{"Azure endpoint", portainer.Endpoint{Type: portainer.AzureEnvironment}, CloudProviderAzure},
+		{"No signal", endpointWithEngineInfo(map[string]interface{}{"KernelVersion": "5.4.0-42-generic", "OperatingSystem": "Ubuntu 20.04 LTS"}), CloudProviderOnPrem},
+		{"No snapshot", portainer.Endpoint{Type: portainer.DockerEnvironment}, CloudProviderUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := detectCloudProvider(&c.endpoint)
+			if provider != c.expected {
+				t.Errorf("Expected cloud provider to be %s, but it was %s instead", c.expected, provider)
+			}
+		})
+	}
+}
+
+func TestComputeEndpointCloudProviderDistribution(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		endpointWithEngineInfo(map[string]interface{}{"KernelVersion": "4.14.173-137.229.amzn2.x86_64"}),
+		endpointWithEngineInfo(map[string]interface{}{"KernelVersion": "4.14.173-137.229.amzn2.x86_64"}),
+		{Type: portainer.DockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.CloudProviderDistribution[CloudProviderAWS] != 2 {
+		t.Errorf("Expected 2 AWS endpoints, but got %d", data.Endpoint.CloudProviderDistribution[CloudProviderAWS])
+	}
+	if data.Endpoint.CloudProviderDistribution[CloudProviderUnknown] != 1 {
+		t.Errorf("Expected 1 unknown endpoint, but got %d", data.Endpoint.CloudProviderDistribution[CloudProviderUnknown])
+	}
+	if len(data.Endpoint.Endpoints) != 3 {
+		t.Errorf("Expected 3 endpoint entries, but got %d", len(data.Endpoint.Endpoints))
+	}
+}
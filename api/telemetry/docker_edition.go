@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// DockerEditionCE represents an endpoint running the Docker Community Edition
+	DockerEditionCE = "ce"
+	// DockerEditionEE represents an endpoint running the Docker Enterprise Edition
+	DockerEditionEE = "ee"
+	// DockerEditionMirantis represents an endpoint running the Mirantis Container Runtime
+	DockerEditionMirantis = "mirantis"
+	// DockerEditionUnknown represents an endpoint for which the Docker edition cannot be determined
+	DockerEditionUnknown = "unknown"
+)
+
+// snapshotEngineVersion represents the subset of the Docker engine version stored
+// inside a snapshot that is used by the telemetry job.
+type snapshotEngineVersion struct {
+	Platform struct {
+		Name string `json:"Name"`
+	} `json:"Platform"`
+	Version string `json:"Version"`
+}
+
+// detectDockerEdition returns the edition of the Docker engine of an endpoint, based on the
+// platform name (e.g. "Docker Engine - Community") and the version suffix (e.g. "18.09.11-ee")
+// reported by the engine in the latest snapshot.
+func detectDockerEdition(endpoint *portainer.Endpoint) string {
+	if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].SnapshotRaw.Version == nil {
+		return DockerEditionUnknown
+	}
+
+	var version snapshotEngineVersion
+	err := decodeSnapshotRaw(endpoint.Snapshots[0].SnapshotRaw.Version, &version)
+	if err != nil {
+		return DockerEditionUnknown
+	}
+
+	platform := strings.ToLower(version.Platform.Name)
+	switch {
+	case strings.Contains(platform, "mirantis"):
+		return DockerEditionMirantis
+	case strings.Contains(platform, "enterprise"):
+		return DockerEditionEE
+	case strings.Contains(platform, "community"):
+		return DockerEditionCE
+	}
+
+	engineVersion := strings.ToLower(version.Version)
+	switch {
+	case strings.HasSuffix(engineVersion, "-ee"):
+		return DockerEditionEE
+	case strings.HasSuffix(engineVersion, "-ce"):
+		return DockerEditionCE
+	}
+
+	return DockerEditionUnknown
+}
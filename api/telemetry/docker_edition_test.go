@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func endpointWithEngineVersion(version map[string]interface{}) portainer.Endpoint {
+	return portainer.Endpoint{
+		Type:      portainer.DockerEnvironment,
+		Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Version: version}}},
+	}
+}
+
+func TestDetectDockerEdition(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint portainer.Endpoint
+		expected string
+	}{
+		{"CE platform", endpointWithEngineVersion(map[string]interface{}{"Platform": map[string]interface{}{"Name": "Docker Engine - Community"}, "Version": "19.03.5"}), DockerEditionCE},
+		{"EE platform", endpointWithEngineVersion(map[string]interface{}{"Platform": map[string]interface{}{"Name": "Docker Engine - Enterprise"}, "Version": "19.03.5"}), DockerEditionEE},
+		{"Mirantis platform", endpointWithEngineVersion(map[string]interface{}{"Platform": map[string]interface{}{"Name": "Mirantis Container Runtime"}, "Version": "20.10.5"}), DockerEditionMirantis},
+		{"CE version", endpointWithEngineVersion(map[string]interface{}{"Version": "18.06.1-ce"}), DockerEditionCE},
+		{"EE version", endpointWithEngineVersion(map[string]interface{}{"Version": "18.09.11-ee"}), DockerEditionEE},
+		{"No signal", endpointWithEngineVersion(map[string]interface{}{"Version": "1.13.1"}), DockerEditionUnknown},
+		{"No snapshot", portainer.Endpoint{Type: portainer.DockerEnvironment}, DockerEditionUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			edition := detectDockerEdition(&c.endpoint)
+			if edition != c.expected {
+				t.Errorf("Expected Docker edition %s, but got %s", c.expected, edition)
+			}
+		})
+	}
+}
+
+func TestComputeEndpointDockerEditionTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		endpointWithEngineVersion(map[string]interface{}{"Version": "18.06.1-ce"}),
+		endpointWithEngineVersion(map[string]interface{}{"Version": "18.09.11-ee"}),
+		{Type: portainer.AzureEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	distribution := data.Endpoint.DockerEditionDistribution
+	if len(distribution) != 2 || distribution[DockerEditionCE] != 1 || distribution[DockerEditionEE] != 1 {
+		t.Errorf("Expected 1 CE and 1 EE endpoint, but got %v", distribution)
+	}
+	if data.Endpoint.Endpoints[0].DockerEdition != DockerEditionCE {
+		t.Errorf("Expected the first endpoint to run the CE edition, but got %s", data.Endpoint.Endpoints[0].DockerEdition)
+	}
+	if data.Endpoint.Endpoints[2].DockerEdition != "" {
+		t.Errorf("Expected no Docker edition for the Azure endpoint, but got %s", data.Endpoint.Endpoints[2].DockerEdition)
+	}
+}
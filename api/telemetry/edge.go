@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// connectedEdgeAgentsUnavailable is reported when the reverse tunnel service is not available
+	connectedEdgeAgentsUnavailable = -1
+	// wildcardTunnelServerAddress is the default address of the tunnel server, listening on every interface
+	wildcardTunnelServerAddress = "0.0.0.0"
+
+	// ScheduleJobTypeScriptExecution represents a schedule executing a script against endpoints
+	ScheduleJobTypeScriptExecution = "script_execution"
+	// ScheduleJobTypeSnapshot represents the system schedule creating the endpoint snapshots
+	ScheduleJobTypeSnapshot = "snapshot"
+	// ScheduleJobTypeEndpointSync represents the system schedule synchronizing the endpoints
+	ScheduleJobTypeEndpointSync = "endpoint_sync"
+	// ScheduleJobTypeTelemetry represents the system schedule sending the telemetry data
+	ScheduleJobTypeTelemetry = "telemetry"
+	// ScheduleJobTypeUnknown represents a schedule with an unknown job type
+	ScheduleJobTypeUnknown = "unknown"
+)
+
+// computeEdgeComputeTelemetry computes the Edge compute section. The Edge agents currently connected
+// to the tunnel server are counted from the reverse tunnel service, this state is only kept in memory
+// and is not read from the store. Only the presence of the Portainer URL and of the tunnel server
+// address is reported, never their values.
+func computeEdgeComputeTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	endpoints, err := context.endpoints()
+	if err != nil {
+		return err
+	}
+
+	schedules, err := context.schedules()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.settings()
+	if err != nil {
+		return err
+	}
+
+	// The check-in interval is configured globally, every Edge agent uses the same interval
+	data.EdgeCompute.EdgeCheckinInterval = settings.EdgeAgentCheckinInterval
+	if data.EdgeCompute.EdgeCheckinInterval <= 0 {
+		data.EdgeCompute.EdgeCheckinInterval = portainer.DefaultEdgeAgentCheckinIntervalInSeconds
+	}
+
+	data.EdgeCompute.TunnelServerAddressConfigured = context.TunnelServerAddress != "" && context.TunnelServerAddress != wildcardTunnelServerAddress
+
+	data.EdgeCompute.Schedule.JobTypeCounts = make(map[string]int)
+	for _, schedule := range schedules {
+		data.EdgeCompute.Schedule.JobTypeCounts[scheduleJobTypeTelemetry(schedule.JobType)]++
+	}
+
+	if context.reverseTunnelService == nil {
+		data.EdgeCompute.ConnectedEdgeAgents = connectedEdgeAgentsUnavailable
+		data.EdgeCompute.ActiveTunnels = connectedEdgeAgentsUnavailable
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Type != portainer.EdgeAgentEnvironment {
+			continue
+		}
+
+		// The Portainer host used by the Edge agents is stored as the URL of the Edge endpoint
+		if endpoint.URL != "" {
+			data.EdgeCompute.EdgePortainerURLConfigured = true
+		}
+
+		if context.reverseTunnelService == nil {
+			continue
+		}
+
+		tunnel := context.reverseTunnelService.GetTunnelDetails(endpoint.ID)
+		if tunnel.Status == portainer.EdgeAgentActive {
+			data.EdgeCompute.ConnectedEdgeAgents++
+		}
+		// A tunnel is opened by the agent once it is required, both states load the tunnel server
+		if tunnel.Status == portainer.EdgeAgentActive || tunnel.Status == portainer.EdgeAgentManagementRequired {
+			data.EdgeCompute.ActiveTunnels++
+		}
+	}
+
+	return nil
+}
+
+// scheduleJobTypeTelemetry returns the telemetry representation of a schedule job type
+func scheduleJobTypeTelemetry(jobType portainer.JobType) string {
+	switch jobType {
+	case portainer.ScriptExecutionJobType:
+		return ScheduleJobTypeScriptExecution
+	case portainer.SnapshotJobType:
+		return ScheduleJobTypeSnapshot
+	case portainer.EndpointSyncJobType:
+		return ScheduleJobTypeEndpointSync
+	case portainer.TelemetryJobType:
+		return ScheduleJobTypeTelemetry
+	default:
+		return ScheduleJobTypeUnknown
+	}
+}
@@ -0,0 +1,155 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+type fakeReverseTunnelService struct {
+	portainer.ReverseTunnelService
+	activeEndpoints map[portainer.EndpointID]bool
+}
+
+func (service *fakeReverseTunnelService) GetTunnelDetails(endpointID portainer.EndpointID) *portainer.TunnelDetails {
+	if service.activeEndpoints[endpointID] {
+		return &portainer.TunnelDetails{Status: portainer.EdgeAgentActive}
+	}
+	return &portainer.TunnelDetails{Status: portainer.EdgeAgentIdle}
+}
+
+func TestComputeEdgeComputeTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.EdgeAgentEnvironment},
+		{ID: 2, Type: portainer.EdgeAgentEnvironment},
+		{ID: 3, Type: portainer.EdgeAgentEnvironment},
+		{ID: 4, Type: portainer.DockerEnvironment},
+	}}
+	context.reverseTunnelService = &fakeReverseTunnelService{activeEndpoints: map[portainer.EndpointID]bool{1: true, 3: true, 4: true}}
+
+	data := &TelemetryData{}
+	err := computeEdgeComputeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.EdgeCompute.ConnectedEdgeAgents != 2 {
+		t.Errorf("Expected 2 connected Edge agents, but got %d", data.EdgeCompute.ConnectedEdgeAgents)
+	}
+	if data.EdgeCompute.ActiveTunnels != 2 {
+		t.Errorf("Expected 2 active tunnels, but got %d", data.EdgeCompute.ActiveTunnels)
+	}
+}
+
+func TestComputeEdgeComputeTelemetryWithoutTunnelService(t *testing.T) {
+	context := newTestTelemetryJobContext()
+
+	data := &TelemetryData{}
+	err := computeEdgeComputeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.EdgeCompute.ConnectedEdgeAgents != connectedEdgeAgentsUnavailable {
+		t.Errorf("Expected ConnectedEdgeAgents to be %d, but got %d", connectedEdgeAgentsUnavailable, data.EdgeCompute.ConnectedEdgeAgents)
+	}
+}
+
+func TestComputeEdgeComputeConfigurationTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.TunnelServerAddress = wildcardTunnelServerAddress
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.EdgeAgentEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEdgeComputeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.EdgeCompute.EdgePortainerURLConfigured || data.EdgeCompute.TunnelServerAddressConfigured {
+		t.Errorf("Expected the Edge configuration not to be reported, but got %+v", data.EdgeCompute)
+	}
+
+	context.TunnelServerAddress = "10.0.0.1"
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.EdgeAgentEnvironment, URL: "portainer.example.com"},
+	}}
+
+	data = &TelemetryData{}
+	err = computeEdgeComputeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !data.EdgeCompute.EdgePortainerURLConfigured || !data.EdgeCompute.TunnelServerAddressConfigured {
+		t.Errorf("Expected the Edge configuration to be reported, but got %+v", data.EdgeCompute)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(payload), "portainer.example.com") || strings.Contains(string(payload), "10.0.0.1") {
+		t.Errorf("Expected the Edge URLs not to be sent, but got %s", payload)
+	}
+}
+
+func TestComputeEdgeComputeScheduleTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.scheduleService = &fakeScheduleService{schedules: []portainer.Schedule{
+		{ID: 1, JobType: portainer.SnapshotJobType},
+		{ID: 2, JobType: portainer.ScriptExecutionJobType},
+		{ID: 3, JobType: portainer.ScriptExecutionJobType},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEdgeComputeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{ScheduleJobTypeSnapshot: 1, ScheduleJobTypeScriptExecution: 2}
+	if !reflect.DeepEqual(data.EdgeCompute.Schedule.JobTypeCounts, expected) {
+		t.Errorf("Expected job type counts %v, but got %v", expected, data.EdgeCompute.Schedule.JobTypeCounts)
+	}
+}
+
+func TestComputeEdgeComputeTunnelLoadTelemetry(t *testing.T) {
+	cases := []struct {
+		checkinInterval  int
+		expectedInterval int
+	}{
+		{0, portainer.DefaultEdgeAgentCheckinIntervalInSeconds},
+		{5, 5},
+		{300, 300},
+	}
+
+	for _, c := range cases {
+		context := newTestTelemetryJobContext()
+		context.settingsService = &fakeSettingsService{settings: portainer.Settings{EdgeAgentCheckinInterval: c.checkinInterval}}
+		context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+			{ID: 1, Type: portainer.EdgeAgentEnvironment},
+			{ID: 2, Type: portainer.EdgeAgentEnvironment},
+			{ID: 3, Type: portainer.DockerEnvironment},
+		}}
+
+		data := &TelemetryData{}
+		err := computeEdgeComputeTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.EdgeCompute.EdgeCheckinInterval != c.expectedInterval {
+			t.Errorf("Expected the check-in interval to be %ds for a configured interval of %ds, but got %ds", c.expectedInterval, c.checkinInterval, data.EdgeCompute.EdgeCheckinInterval)
+		}
+		if data.EdgeCompute.ActiveTunnels != connectedEdgeAgentsUnavailable {
+			t.Errorf("Expected ActiveTunnels to be %d without tunnel service, but got %d", connectedEdgeAgentsUnavailable, data.EdgeCompute.ActiveTunnels)
+		}
+	}
+}
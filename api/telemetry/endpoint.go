@@ -0,0 +1,273 @@
+package telemetry
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// EndpointTypeDocker represents an endpoint connected to a Docker environment
+	EndpointTypeDocker = "docker"
+	// EndpointTypeAgent represents an endpoint connected to a Portainer agent
+	EndpointTypeAgent = "agent"
+	// EndpointTypeAzure represents an endpoint connected to an Azure environment
+	EndpointTypeAzure = "azure"
+	// EndpointTypeEdge represents an endpoint connected to an Edge agent
+	EndpointTypeEdge = "edge"
+
+	// imageScanningUnavailable is reported as the number of vulnerable images and critical vulnerabilities,
+	// the images are not scanned and no scan result is stored.
+	imageScanningUnavailable = -1
+
+	// snapshotSuccessRateNotTracked is reported as the snapshot success rate of the endpoints for which
+	// no snapshot attempt is recorded: Azure endpoints, which are never snapshotted, Edge endpoints whose
+	// agent has not opened a tunnel yet and endpoints that were not snapshotted since the attempts are recorded.
+	snapshotSuccessRateNotTracked = -1
+
+	defaultDockerPort    = "2375"
+	defaultDockerTLSPort = "2376"
+	defaultAgentPort     = "9001"
+)
+
+func computeEndpointTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	endpoints, err := context.endpoints()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.settings()
+	if err != nil {
+		return err
+	}
+
+	// An invalid interval leaves SnapshottedInLastInterval to 0
+	snapshotInterval, _ := time.ParseDuration(settings.SnapshotInterval)
+	now := context.now()
+
+	data.Endpoint.Count = len(endpoints)
+	data.Endpoint.CloudProviderDistribution = make(map[string]int)
+	data.Endpoint.DockerEditionDistribution = make(map[string]int)
+	data.Endpoint.Endpoints = make([]EndpointEnvironmentTelemetryData, 0)
+	snapshotSuccessRates := make([]float64, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		context.waitRateLimit()
+
+		if sanitizeEndpointSnapshot(&endpoint) {
+			data.Endpoint.CorruptSnapshotCount++
+		}
+
+		environment := EndpointEnvironmentTelemetryData{
+			CloudProvider:       detectCloudProvider(&endpoint),
+			NetworkDrivers:      endpointNetworkDrivers(&endpoint),
+			SnapshotSuccessRate: snapshotSuccessRate(&endpoint),
+		}
+		snapshotSuccessRates = append(snapshotSuccessRates, environment.SnapshotSuccessRate)
+
+		switch endpoint.Type {
+		case portainer.DockerEnvironment:
+			data.Endpoint.DockerCount++
+			environment.Type = EndpointTypeDocker
+			computeEndpointURLTelemetry(&endpoint, data)
+		case portainer.AgentOnDockerEnvironment:
+			data.Endpoint.AgentCount++
+			environment.Type = EndpointTypeAgent
+			computeEndpointURLTelemetry(&endpoint, data)
+
+			switch agentVersionStatus(&endpoint, context.MinimumAgentVersion) {
+			case agentVersionOutdated:
+				data.Endpoint.OutdatedAgentCount++
+			case agentVersionUnknown:
+				data.Endpoint.UnknownAgentVersionCount++
+			}
+		case portainer.AzureEnvironment:
+			data.Endpoint.AzureCount++
+			environment.Type = EndpointTypeAzure
+		case portainer.EdgeAgentEnvironment:
+			data.Endpoint.EdgeCount++
+			environment.Type = EndpointTypeEdge
+			if isPendingEdgeEndpoint(&endpoint) {
+				data.Endpoint.PendingEdgeCount++
+			}
+		}
+
+		data.Endpoint.CloudProviderDistribution[environment.CloudProvider]++
+
+		// Azure endpoints do not run a Docker engine
+		if endpoint.Type != portainer.AzureEnvironment {
+			environment.DockerEdition = detectDockerEdition(&endpoint)
+			data.Endpoint.DockerEditionDistribution[environment.DockerEdition]++
+		}
+		if !context.PrivacyMode && context.sampleEndpoint() {
+			data.Endpoint.Endpoints = append(data.Endpoint.Endpoints, environment)
+		}
+
+		if endpoint.Status == portainer.EndpointStatusDown {
+			data.Endpoint.UnreachableCount++
+		}
+
+		// The creation source is not recorded for the endpoints created before it was introduced
+		// and for the endpoint created from the --host flag
+		switch endpoint.CreationSource {
+		case portainer.EndpointCreatedViaAPI:
+			data.Endpoint.CreatedViaAPI++
+		case portainer.EndpointCreatedViaUI:
+			data.Endpoint.CreatedViaUI++
+		default:
+			data.Endpoint.CreatedViaUnknown++
+		}
+
+		if endpoint.TLSConfig.TLS && endpoint.TLSConfig.TLSSkipVerify {
+			data.Endpoint.TLSSkipVerifyCount++
+		}
+
+		if hasRegistryMirror(&endpoint) {
+			data.Endpoint.EndpointsWithRegistryMirror++
+		}
+
+		if hasSnapshotError(&endpoint) {
+			data.Endpoint.SnapshotErrorCount++
+		}
+
+		if snapshotInterval > 0 && len(endpoint.Snapshots) > 0 {
+			snapshotTime := time.Unix(endpoint.Snapshots[0].Time, 0)
+			if now.Sub(snapshotTime) <= snapshotInterval {
+				data.Endpoint.SnapshottedInLastInterval++
+			}
+		}
+	}
+
+	data.Endpoint.VulnerableImages = imageScanningUnavailable
+	data.Endpoint.CriticalVulnerabilities = imageScanningUnavailable
+
+	data.Endpoint.AverageSnapshotSuccessRate = averageSnapshotSuccessRate(snapshotSuccessRates)
+
+	data.Endpoint.SampledCount = len(data.Endpoint.Endpoints)
+	data.Endpoint.SampleRate = context.EndpointSampleRate
+
+	// Direct endpoints are connected to the Docker API, Azure endpoints are not part of any category
+	data.Endpoint.AgentPercent = percentage(data.Endpoint.AgentCount, data.Endpoint.Count)
+	data.Endpoint.EdgePercent = percentage(data.Endpoint.EdgeCount, data.Endpoint.Count)
+	data.Endpoint.DirectPercent = percentage(data.Endpoint.DockerCount, data.Endpoint.Count)
+
+	return nil
+}
+
+// sampleEndpoint returns true when an endpoint must be included in the per-endpoint entries,
+// with a probability of EndpointSampleRate.
+func (context *TelemetryJobContext) sampleEndpoint() bool {
+	if context.EndpointSampleRate >= 1 {
+		return true
+	}
+	return context.randFloat() < context.EndpointSampleRate
+}
+
+// averageSnapshotSuccessRate returns the average of the snapshot success rates that are tracked,
+// or snapshotSuccessRateNotTracked when none of them is.
+func averageSnapshotSuccessRate(rates []float64) float64 {
+	var sum float64
+	tracked := 0
+	for _, rate := range rates {
+		if rate < 0 {
+			continue
+		}
+		sum += rate
+		tracked++
+	}
+
+	if tracked == 0 {
+		return snapshotSuccessRateNotTracked
+	}
+	return sum / float64(tracked)
+}
+
+// percentage returns count as a percentage of total, or 0 when total is 0
+func percentage(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(total)
+}
+
+// computeEndpointURLTelemetry counts the endpoints connected through a socket (unix:// or npipe://)
+// and the TCP endpoints that do not use the default Docker (2375/2376) or agent (9001) port.
+func computeEndpointURLTelemetry(endpoint *portainer.Endpoint, data *TelemetryData) {
+	if strings.HasPrefix(endpoint.URL, "unix://") || strings.HasPrefix(endpoint.URL, "npipe://") {
+		data.Endpoint.SocketCount++
+		return
+	}
+
+	endpointURL, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return
+	}
+
+	switch endpointHostExposure(endpointURL.Hostname()) {
+	case hostExposurePublic:
+		data.Endpoint.PubliclyExposedCount++
+	case hostExposureUnknown:
+		data.Endpoint.ExposureUnknownCount++
+	}
+
+	port := endpointURL.Port()
+	if port == "" {
+		return
+	}
+
+	if endpoint.Type == portainer.AgentOnDockerEnvironment {
+		if port != defaultAgentPort {
+			data.Endpoint.NonDefaultPortCount++
+		}
+		return
+	}
+
+	if port != defaultDockerPort && port != defaultDockerTLSPort {
+		data.Endpoint.NonDefaultPortCount++
+	}
+}
+
+// isPendingEdgeEndpoint returns true when an Edge key was generated for the endpoint
+// but no Edge agent ever checked in: the Edge identifier is only associated to the endpoint
+// during the first agent check-in.
+func isPendingEdgeEndpoint(endpoint *portainer.Endpoint) bool {
+	return endpoint.EdgeKey != "" && endpoint.EdgeID == ""
+}
+
+// hasSnapshotError returns true when the latest snapshot attempt of the endpoint failed, whether
+// it was triggered by the snapshot job or by a user. Endpoints marked as down manually are not counted.
+func hasSnapshotError(endpoint *portainer.Endpoint) bool {
+	return endpoint.LastSnapshotFailed
+}
+
+// snapshotSuccessRate returns the ratio (0-1) of the snapshot attempts of the endpoint that succeeded,
+// or snapshotSuccessRateNotTracked when no attempt is recorded.
+func snapshotSuccessRate(endpoint *portainer.Endpoint) float64 {
+	if endpoint.SnapshotAttempts <= 0 {
+		return snapshotSuccessRateNotTracked
+	}
+
+	failures := endpoint.SnapshotFailures
+	if failures > endpoint.SnapshotAttempts {
+		failures = endpoint.SnapshotAttempts
+	}
+	return float64(endpoint.SnapshotAttempts-failures) / float64(endpoint.SnapshotAttempts)
+}
+
+// hasRegistryMirror returns true when the Docker engine of an endpoint is configured with at least
+// one registry mirror (registry-mirrors in daemon.json), as reported in the latest snapshot.
+func hasRegistryMirror(endpoint *portainer.Endpoint) bool {
+	if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].SnapshotRaw.Info == nil {
+		return false
+	}
+
+	var info snapshotEngineInfo
+	err := decodeSnapshotRaw(endpoint.Snapshots[0].SnapshotRaw.Info, &info)
+	if err != nil {
+		return false
+	}
+
+	return len(info.RegistryConfig.Mirrors) > 0
+}
@@ -0,0 +1,353 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeEndpointTelemetry(t *testing.T) {
+	t.Run("Connected Edge endpoint", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+			{ID: 1, Type: portainer.EdgeAgentEnvironment, EdgeKey: "key", EdgeID: "edge-id", Status: portainer.EndpointStatusUp},
+		}}
+
+		data := &TelemetryData{}
+		err := computeEndpointTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Endpoint.EdgeCount != 1 {
+			t.Errorf("Expected EdgeCount to be 1, but it was %d instead", data.Endpoint.EdgeCount)
+		}
+		if data.Endpoint.PendingEdgeCount != 0 {
+			t.Errorf("Expected PendingEdgeCount to be 0, but it was %d instead", data.Endpoint.PendingEdgeCount)
+		}
+	})
+
+	t.Run("Pending Edge endpoint", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+			{ID: 1, Type: portainer.EdgeAgentEnvironment, EdgeKey: "key", Status: portainer.EndpointStatusUp},
+			{ID: 2, Type: portainer.DockerEnvironment, Status: portainer.EndpointStatusUp},
+		}}
+
+		data := &TelemetryData{}
+		err := computeEndpointTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Endpoint.Count != 2 {
+			t.Errorf("Expected Count to be 2, but it was %d instead", data.Endpoint.Count)
+		}
+		if data.Endpoint.PendingEdgeCount != 1 {
+			t.Errorf("Expected PendingEdgeCount to be 1, but it was %d instead", data.Endpoint.PendingEdgeCount)
+		}
+	})
+}
+
+func TestComputeEndpointURLTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, URL: "tcp://10.0.0.1:2999"},
+		{ID: 2, Type: portainer.DockerEnvironment, URL: "tcp://10.0.0.2:2376"},
+		{ID: 3, Type: portainer.AgentOnDockerEnvironment, URL: "tcp://tasks.agent:9001"},
+		{ID: 4, Type: portainer.DockerEnvironment, URL: "unix:///var/run/docker.sock"},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.NonDefaultPortCount != 1 {
+		t.Errorf("Expected NonDefaultPortCount to be 1, but it was %d instead", data.Endpoint.NonDefaultPortCount)
+	}
+	if data.Endpoint.SocketCount != 1 {
+		t.Errorf("Expected SocketCount to be 1, but it was %d instead", data.Endpoint.SocketCount)
+	}
+}
+
+func TestComputeEndpointTLSSkipVerifyTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, TLSConfig: portainer.TLSConfiguration{TLS: true}},
+		{ID: 2, Type: portainer.DockerEnvironment, TLSConfig: portainer.TLSConfiguration{TLS: true, TLSSkipVerify: true}},
+		{ID: 3, Type: portainer.DockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.TLSSkipVerifyCount != 1 {
+		t.Errorf("Expected TLSSkipVerifyCount to be 1, but it was %d instead", data.Endpoint.TLSSkipVerifyCount)
+	}
+}
+
+func TestComputeEndpointSnapshotTelemetry(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	context := newTestTelemetryJobContext()
+	context.now = func() time.Time { return now }
+	context.settingsService = &fakeSettingsService{settings: portainer.Settings{SnapshotInterval: "5m"}}
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{Time: now.Add(-2 * time.Minute).Unix()}}},
+		{ID: 2, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{Time: now.Add(-1 * time.Hour).Unix()}}},
+		{ID: 3, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{}},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.SnapshottedInLastInterval != 1 {
+		t.Errorf("Expected SnapshottedInLastInterval to be 1, but it was %d instead", data.Endpoint.SnapshottedInLastInterval)
+	}
+}
+
+func TestComputeEndpointSnapshotErrorTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, Status: portainer.EndpointStatusDown, Snapshots: []portainer.Snapshot{{Time: 1}}, SnapshotAttempts: 2, SnapshotFailures: 1, LastSnapshotFailed: true},
+		{ID: 2, Type: portainer.DockerEnvironment, Status: portainer.EndpointStatusDown, Snapshots: []portainer.Snapshot{{Time: 1}}, SnapshotAttempts: 2},
+		{ID: 3, Type: portainer.DockerEnvironment, Status: portainer.EndpointStatusUp, Snapshots: []portainer.Snapshot{{Time: 1}}, SnapshotAttempts: 2, SnapshotFailures: 1},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.SnapshotErrorCount != 1 {
+		t.Errorf("Expected SnapshotErrorCount to be 1, but it was %d instead", data.Endpoint.SnapshotErrorCount)
+	}
+	if data.Endpoint.UnreachableCount != 2 {
+		t.Errorf("Expected UnreachableCount to be 2, but it was %d instead", data.Endpoint.UnreachableCount)
+	}
+}
+
+func TestComputeEndpointTelemetryPrivacyMode(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.PrivacyMode = true
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment},
+		{ID: 2, Type: portainer.AgentOnDockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.Count != 2 {
+		t.Errorf("Expected the endpoint count to be 2, but it was %d instead", data.Endpoint.Count)
+	}
+
+	payload, err := json.Marshal(data.Endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(payload), `"Endpoints":[]`) {
+		t.Errorf("Expected the endpoints array to be empty, but got %s", payload)
+	}
+}
+
+func TestComputeEndpointPercentages(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment},
+		{ID: 2, Type: portainer.AgentOnDockerEnvironment},
+		{ID: 3, Type: portainer.AgentOnDockerEnvironment},
+		{ID: 4, Type: portainer.EdgeAgentEnvironment},
+		{ID: 5, Type: portainer.EdgeAgentEnvironment},
+		{ID: 6, Type: portainer.EdgeAgentEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total := data.Endpoint.AgentPercent + data.Endpoint.EdgePercent + data.Endpoint.DirectPercent
+	if math.Abs(total-100) > 0.001 {
+		t.Errorf("Expected the percentages to sum to 100, but they summed to %f", total)
+	}
+	if math.Abs(data.Endpoint.EdgePercent-50) > 0.001 {
+		t.Errorf("Expected EdgePercent to be 50, but it was %f instead", data.Endpoint.EdgePercent)
+	}
+
+	data = &TelemetryData{}
+	context.endpointService = &fakeEndpointService{}
+	err = computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Endpoint.AgentPercent != 0 || data.Endpoint.EdgePercent != 0 || data.Endpoint.DirectPercent != 0 {
+		t.Errorf("Expected the percentages to be 0 without endpoints, but got %+v", data.Endpoint)
+	}
+}
+
+func TestComputeEndpointTelemetrySampling(t *testing.T) {
+	endpoints := make([]portainer.Endpoint, 0)
+	for i := 1; i <= 10; i++ {
+		endpoints = append(endpoints, portainer.Endpoint{ID: portainer.EndpointID(i), Type: portainer.AgentOnDockerEnvironment})
+	}
+
+	draws := []float64{0.1, 0.9, 0.2, 0.8, 0.3, 0.7, 0.4, 0.6, 0.45, 0.55}
+	draw := 0
+
+	context := newTestTelemetryJobContext()
+	context.EndpointSampleRate = 0.5
+	context.randFloat = func() float64 {
+		value := draws[draw%len(draws)]
+		draw++
+		return value
+	}
+	context.endpointService = &fakeEndpointService{endpoints: endpoints}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.Count != 10 || data.Endpoint.AgentCount != 10 {
+		t.Errorf("Expected the aggregated counts to include every endpoint, but got Count=%d and AgentCount=%d", data.Endpoint.Count, data.Endpoint.AgentCount)
+	}
+	if data.Endpoint.SampledCount != 5 || len(data.Endpoint.Endpoints) != 5 {
+		t.Errorf("Expected 5 sampled endpoints, but got SampledCount=%d with %d entries", data.Endpoint.SampledCount, len(data.Endpoint.Endpoints))
+	}
+	if data.Endpoint.SampleRate != 0.5 {
+		t.Errorf("Expected SampleRate to be 0.5, but it was %f instead", data.Endpoint.SampleRate)
+	}
+}
+
+func TestComputeEndpointVulnerabilityTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{{ID: 1, Type: portainer.DockerEnvironment}}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.VulnerableImages != imageScanningUnavailable {
+		t.Errorf("Expected VulnerableImages to be %d, but it was %d instead", imageScanningUnavailable, data.Endpoint.VulnerableImages)
+	}
+	if data.Endpoint.CriticalVulnerabilities != imageScanningUnavailable {
+		t.Errorf("Expected CriticalVulnerabilities to be %d, but it was %d instead", imageScanningUnavailable, data.Endpoint.CriticalVulnerabilities)
+	}
+}
+
+func TestAverageSnapshotSuccessRate(t *testing.T) {
+	cases := []struct {
+		name     string
+		rates    []float64
+		expected float64
+	}{
+		{"known rates", []float64{1, 0.5, 0.75}, 0.75},
+		{"untracked rates are ignored", []float64{1, snapshotSuccessRateNotTracked, 0.5}, 0.75},
+		{"no tracked rate", []float64{snapshotSuccessRateNotTracked}, snapshotSuccessRateNotTracked},
+		{"no endpoint", nil, snapshotSuccessRateNotTracked},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if average := averageSnapshotSuccessRate(c.rates); average != c.expected {
+				t.Errorf("Expected the average snapshot success rate to be %f, but it was %f instead", c.expected, average)
+			}
+		})
+	}
+}
+
+func TestComputeEndpointSnapshotSuccessRateTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, SnapshotAttempts: 4, SnapshotFailures: 1},
+		{ID: 2, Type: portainer.DockerEnvironment, SnapshotAttempts: 2, SnapshotFailures: 2},
+		{ID: 3, Type: portainer.EdgeAgentEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []float64{0.75, 0, snapshotSuccessRateNotTracked}
+	for i, rate := range expected {
+		if data.Endpoint.Endpoints[i].SnapshotSuccessRate != rate {
+			t.Errorf("Expected the SnapshotSuccessRate of endpoint %d to be %f, but it was %f instead", i+1, rate, data.Endpoint.Endpoints[i].SnapshotSuccessRate)
+		}
+	}
+	if data.Endpoint.AverageSnapshotSuccessRate != 0.375 {
+		t.Errorf("Expected AverageSnapshotSuccessRate to be 0.375, but it was %f instead", data.Endpoint.AverageSnapshotSuccessRate)
+	}
+}
+
+func TestComputeEndpointCreationSourceTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, CreationSource: portainer.EndpointCreatedViaAPI},
+		{ID: 2, Type: portainer.DockerEnvironment, CreationSource: portainer.EndpointCreatedViaUI},
+		{ID: 3, Type: portainer.AgentOnDockerEnvironment, CreationSource: portainer.EndpointCreatedViaUI},
+		{ID: 4, Type: portainer.DockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.CreatedViaAPI != 1 || data.Endpoint.CreatedViaUI != 2 || data.Endpoint.CreatedViaUnknown != 1 {
+		t.Errorf("Expected 1 endpoint created via the API, 2 via the UI and 1 unknown, but got %d, %d and %d", data.Endpoint.CreatedViaAPI, data.Endpoint.CreatedViaUI, data.Endpoint.CreatedViaUnknown)
+	}
+}
+
+func TestComputeEndpointRegistryMirrorTelemetry(t *testing.T) {
+	withMirror := map[string]interface{}{
+		"RegistryConfig": map[string]interface{}{
+			"Mirrors": []interface{}{"https://mirror.example.com/"},
+		},
+	}
+	withoutMirror := map[string]interface{}{
+		"RegistryConfig": map[string]interface{}{
+			"Mirrors": []interface{}{},
+		},
+	}
+
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Info: withMirror}}}},
+		{ID: 2, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Info: withoutMirror}}}},
+		{ID: 3, Type: portainer.DockerEnvironment},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.EndpointsWithRegistryMirror != 1 {
+		t.Errorf("Expected 1 endpoint with a registry mirror, but got %d", data.Endpoint.EndpointsWithRegistryMirror)
+	}
+}
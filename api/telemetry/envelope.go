@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"time"
+)
+
+// Envelope represents the payload sent to the telemetry server: the telemetry data, or a batch of
+// telemetry data, along with transport metadata independent of its content.
+type Envelope struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	SentAt        string      `json:"sentAt"`
+	Data          interface{} `json:"data"`
+}
+
+// buildEnvelope wraps the data sent to the telemetry server. The schema version is the version
+// targeted by the context, or the current version when every field is sent.
+func (context *TelemetryJobContext) buildEnvelope(data interface{}) *Envelope {
+	schemaVersion := context.SchemaVersion
+	if schemaVersion <= 0 {
+		schemaVersion = CurrentSchemaVersion
+	}
+
+	return &Envelope{
+		SchemaVersion: schemaVersion,
+		SentAt:        context.now().UTC().Format(time.RFC3339),
+		Data:          data,
+	}
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSendTelemetryEnvelope(t *testing.T) {
+	var envelope map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&envelope)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.now = func() time.Time { return time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC) }
+
+	err := context.sendTelemetry(&TelemetryData{TelemetryID: "id"}, &TelemetrySendResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(envelope) != 3 {
+		t.Fatalf("Expected the envelope to contain 3 fields, but got %v", envelope)
+	}
+	if string(envelope["schemaVersion"]) != strconv.Itoa(CurrentSchemaVersion) {
+		t.Errorf("Expected schemaVersion to be the current schema version, but got %s", envelope["schemaVersion"])
+	}
+	if string(envelope["sentAt"]) != `"2020-01-01T12:00:00Z"` {
+		t.Errorf("Expected sentAt to use the clock of the context, but got %s", envelope["sentAt"])
+	}
+
+	var data TelemetryData
+	err = json.Unmarshal(envelope["data"], &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TelemetryID != "id" {
+		t.Errorf("Expected the telemetry data to be wrapped in the envelope, but got %s", envelope["data"])
+	}
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import "fmt"
+
+const (
+	// SectionIdentifier represents the identifier section of the telemetry data
+	SectionIdentifier = "identifier"
+	// SectionEdgeCompute represents the edge compute section of the telemetry data
+	SectionEdgeCompute = "edge_compute"
+	// SectionEndpoint represents the endpoint section of the telemetry data
+	SectionEndpoint = "endpoint"
+	// SectionRegistry represents the registry section of the telemetry data
+	SectionRegistry = "registry"
+	// SectionResourceControl represents the resource control section of the telemetry data
+	SectionResourceControl = "resource_control"
+	// SectionSettings represents the settings section of the telemetry data
+	SectionSettings = "settings"
+	// SectionStack represents the stack section of the telemetry data
+	SectionStack = "stack"
+	// SectionTeam represents the team section of the telemetry data
+	SectionTeam = "team"
+	// SectionUser represents the user section of the telemetry data
+	SectionUser = "user"
+	// SectionWebhook represents the webhook section of the telemetry data
+	SectionWebhook = "webhook"
+	// SectionRuntime represents the runtime section of the telemetry data
+	SectionRuntime = "runtime"
+	// SectionSecurity represents the security section of the telemetry data
+	SectionSecurity = "security"
+	// SectionProcess represents the process section of the telemetry data
+	SectionProcess = "process"
+)
+
+// SectionError represents an error that occurred while computing a section of the telemetry data
+type SectionError struct {
+	Section string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *SectionError) Error() string {
+	return fmt.Sprintf("unable to compute telemetry section %s: %s", e.Section, e.Err)
+}
+
+// TelemetrySendResult represents the outcome of a telemetry run.
+// CompressedBytes and CompressionRatio are only set when the payload is compressed.
+// Throttled is set when the send was skipped because the last submission is too recent,
+// WithinStartupGrace when it was skipped because the process started too recently.
+// RetryBudgetRemaining is the number of retries left in the retry budget at the end of the run.
+type TelemetrySendResult struct {
+	Sent                 bool
+	Throttled            bool
+	WithinStartupGrace   bool
+	SectionErrors        []*SectionError
+	Err                  error
+	RawBytes             int
+	CompressedBytes      int
+	CompressionRatio     float64
+	RetryBudgetRemaining int
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestRunWithResultSectionError(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.registryService = &failingRegistryService{}
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	var registryError *SectionError
+	for _, sectionError := range result.SectionErrors {
+		if sectionError.Section == SectionRegistry {
+			registryError = sectionError
+		}
+	}
+
+	if registryError == nil {
+		t.Fatalf("Expected a section error for the registry section, but got %v", result.SectionErrors)
+	}
+	if registryError.Err == nil {
+		t.Errorf("Expected the registry section error to wrap the read failure")
+	}
+}
+
+type panickingTeamService struct {
+	portainer.TeamService
+}
+
+func (service *panickingTeamService) Teams() ([]portainer.Team, error) {
+	var teams *[]portainer.Team
+	return *teams, nil
+}
+
+func TestRunWithResultRecoversFromSectionPanic(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.teamService = &panickingTeamService{}
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	if len(result.SectionErrors) != 1 || result.SectionErrors[0].Section != SectionTeam {
+		t.Fatalf("Expected a single section error for the team section, but got %v", result.SectionErrors)
+	}
+}
@@ -0,0 +1,51 @@
+package telemetry
+
+import (
+	"net"
+)
+
+const (
+	hostExposurePrivate = iota
+	hostExposurePublic
+	hostExposureUnknown
+)
+
+// privateNetworks lists the private (RFC 1918 and RFC 4193), loopback and link-local networks
+var privateNetworks = parseNetworks(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// endpointHostExposure classifies the host of an endpoint URL as a private or a public address.
+// Host names are never resolved and are classified as unknown.
+func endpointHostExposure(host string) int {
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() {
+		return hostExposureUnknown
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return hostExposurePrivate
+		}
+	}
+
+	return hostExposurePublic
+}
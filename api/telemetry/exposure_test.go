@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestEndpointHostExposure(t *testing.T) {
+	cases := []struct {
+		host     string
+		expected int
+	}{
+		{"10.0.0.1", hostExposurePrivate},
+		{"172.20.1.5", hostExposurePrivate},
+		{"192.168.1.10", hostExposurePrivate},
+		{"127.0.0.1", hostExposurePrivate},
+		{"fd12:3456:789a::1", hostExposurePrivate},
+		{"203.0.113.10", hostExposurePublic},
+		{"2001:4860:4860::8888", hostExposurePublic},
+		{"docker.example.com", hostExposureUnknown},
+		{"", hostExposureUnknown},
+	}
+
+	for _, c := range cases {
+		if exposure := endpointHostExposure(c.host); exposure != c.expected {
+			t.Errorf("Expected host %q to be classified as %d, but got %d", c.host, c.expected, exposure)
+		}
+	}
+}
+
+func TestComputeEndpointExposureTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, URL: "tcp://10.0.0.1:2375"},
+		{ID: 2, Type: portainer.DockerEnvironment, URL: "tcp://203.0.113.10:2376"},
+		{ID: 3, Type: portainer.AgentOnDockerEnvironment, URL: "tcp://docker.example.com:9001"},
+		{ID: 4, Type: portainer.DockerEnvironment, URL: "unix:///var/run/docker.sock"},
+	}}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.PubliclyExposedCount != 1 {
+		t.Errorf("Expected PubliclyExposedCount to be 1, but it was %d instead", data.Endpoint.PubliclyExposedCount)
+	}
+	if data.Endpoint.ExposureUnknownCount != 1 {
+		t.Errorf("Expected ExposureUnknownCount to be 1, but it was %d instead", data.Endpoint.ExposureUnknownCount)
+	}
+}
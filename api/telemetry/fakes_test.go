@@ -0,0 +1,255 @@
+package telemetry
+
+import (
+	"errors"
+
+	"github.com/portainer/portainer/api"
+)
+
+type fakeEndpointService struct {
+	portainer.EndpointService
+	endpoints []portainer.Endpoint
+}
+
+func (service *fakeEndpointService) Endpoints() ([]portainer.Endpoint, error) {
+	return service.endpoints, nil
+}
+
+type fakeRegistryService struct {
+	portainer.RegistryService
+	registries []portainer.Registry
+}
+
+func (service *fakeRegistryService) Registries() ([]portainer.Registry, error) {
+	return service.registries, nil
+}
+
+type fakeSettingsService struct {
+	portainer.SettingsService
+	settings portainer.Settings
+	err      error
+}
+
+func (service *fakeSettingsService) Settings() (*portainer.Settings, error) {
+	if service.err != nil {
+		return nil, service.err
+	}
+	settings := service.settings
+	return &settings, nil
+}
+
+type fakeTeamService struct {
+	portainer.TeamService
+	teams []portainer.Team
+}
+
+func (service *fakeTeamService) Teams() ([]portainer.Team, error) {
+	return service.teams, nil
+}
+
+type fakeTeamMembershipService struct {
+	portainer.TeamMembershipService
+	memberships []portainer.TeamMembership
+}
+
+func (service *fakeTeamMembershipService) TeamMemberships() ([]portainer.TeamMembership, error) {
+	return service.memberships, nil
+}
+
+type fakeUserService struct {
+	portainer.UserService
+	users []portainer.User
+}
+
+func (service *fakeUserService) Users() ([]portainer.User, error) {
+	return service.users, nil
+}
+
+type fakeStackService struct {
+	portainer.StackService
+	stacks []portainer.Stack
+}
+
+func (service *fakeStackService) Stacks() ([]portainer.Stack, error) {
+	return service.stacks, nil
+}
+
+type fakeWebhookService struct {
+	portainer.WebhookService
+	webhooks []portainer.Webhook
+}
+
+func (service *fakeWebhookService) Webhooks() ([]portainer.Webhook, error) {
+	return service.webhooks, nil
+}
+
+type fakeResourceControlService struct {
+	portainer.ResourceControlService
+	resourceControls []portainer.ResourceControl
+}
+
+func (service *fakeResourceControlService) ResourceControls() ([]portainer.ResourceControl, error) {
+	return service.resourceControls, nil
+}
+
+type fakeScheduleService struct {
+	portainer.ScheduleService
+	schedules []portainer.Schedule
+}
+
+func (service *fakeScheduleService) Schedules() ([]portainer.Schedule, error) {
+	return service.schedules, nil
+}
+
+type fakeFileService struct {
+	portainer.FileService
+	files map[string]string
+	reads int
+}
+
+func (service *fakeFileService) GetFileContent(filePath string) ([]byte, error) {
+	service.reads++
+	content, ok := service.files[filePath]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return []byte(content), nil
+}
+
+func (service *fakeFileService) FileExists(filePath string) (bool, error) {
+	_, ok := service.files[filePath]
+	return ok, nil
+}
+
+type fakeTelemetryService struct {
+	configuration *portainer.TelemetryConfiguration
+	updateErr     error
+}
+
+func (service *fakeTelemetryService) Configuration() (*portainer.TelemetryConfiguration, error) {
+	if service.configuration == nil {
+		return nil, portainer.ErrObjectNotFound
+	}
+	configuration := *service.configuration
+	return &configuration, nil
+}
+
+func (service *fakeTelemetryService) UpdateConfiguration(configuration *portainer.TelemetryConfiguration) error {
+	if service.updateErr != nil {
+		return service.updateErr
+	}
+	service.configuration = configuration
+	return nil
+}
+
+func (service *fakeTelemetryService) UpdateLastRun(state portainer.TelemetryRunState) error {
+	if service.configuration == nil {
+		service.configuration = &portainer.TelemetryConfiguration{}
+	}
+	service.configuration.LastRun = state.Time
+	service.configuration.RunSequence++
+	if state.Sent {
+		service.configuration.LastSubmission = state.Time
+		service.configuration.ConsecutiveFailures = 0
+	} else if !state.Throttled {
+		service.configuration.ConsecutiveFailures++
+	}
+	return nil
+}
+
+type fakeTelemetryDataStore struct {
+	endpoints                 []portainer.Endpoint
+	endpointsErr              error
+	registries                []portainer.Registry
+	registriesErr             error
+	resourceControls          []portainer.ResourceControl
+	resourceControlsErr       error
+	schedules                 []portainer.Schedule
+	schedulesErr              error
+	settings                  *portainer.Settings
+	settingsErr               error
+	stacks                    []portainer.Stack
+	stacksErr                 error
+	teams                     []portainer.Team
+	teamsErr                  error
+	teamMemberships           []portainer.TeamMembership
+	teamMembershipsErr        error
+	telemetryConfiguration    *portainer.TelemetryConfiguration
+	telemetryConfigurationErr error
+	users                     []portainer.User
+	usersErr                  error
+	webhooks                  []portainer.Webhook
+	webhooksErr               error
+}
+
+func (store *fakeTelemetryDataStore) Endpoints() ([]portainer.Endpoint, error) {
+	return store.endpoints, store.endpointsErr
+}
+
+func (store *fakeTelemetryDataStore) Registries() ([]portainer.Registry, error) {
+	return store.registries, store.registriesErr
+}
+
+func (store *fakeTelemetryDataStore) ResourceControls() ([]portainer.ResourceControl, error) {
+	return store.resourceControls, store.resourceControlsErr
+}
+
+func (store *fakeTelemetryDataStore) Schedules() ([]portainer.Schedule, error) {
+	return store.schedules, store.schedulesErr
+}
+
+func (store *fakeTelemetryDataStore) Settings() (*portainer.Settings, error) {
+	if store.settingsErr != nil {
+		return nil, store.settingsErr
+	}
+	if store.settings == nil {
+		return &portainer.Settings{}, nil
+	}
+	settings := *store.settings
+	return &settings, nil
+}
+
+func (store *fakeTelemetryDataStore) Stacks() ([]portainer.Stack, error) {
+	return store.stacks, store.stacksErr
+}
+
+func (store *fakeTelemetryDataStore) Teams() ([]portainer.Team, error) {
+	return store.teams, store.teamsErr
+}
+
+func (store *fakeTelemetryDataStore) TeamMemberships() ([]portainer.TeamMembership, error) {
+	return store.teamMemberships, store.teamMembershipsErr
+}
+
+func (store *fakeTelemetryDataStore) TelemetryConfiguration() (*portainer.TelemetryConfiguration, error) {
+	if store.telemetryConfigurationErr != nil {
+		return nil, store.telemetryConfigurationErr
+	}
+	if store.telemetryConfiguration == nil {
+		return nil, portainer.ErrObjectNotFound
+	}
+	configuration := *store.telemetryConfiguration
+	return &configuration, nil
+}
+
+func (store *fakeTelemetryDataStore) Users() ([]portainer.User, error) {
+	return store.users, store.usersErr
+}
+
+func (store *fakeTelemetryDataStore) Webhooks() ([]portainer.Webhook, error) {
+	return store.webhooks, store.webhooksErr
+}
+
+func newTestTelemetryJobContext() *TelemetryJobContext {
+	context := NewTelemetryJobContext(&fakeEndpointService{}, &fakeRegistryService{}, &fakeSettingsService{}, &fakeTeamService{}, &fakeTeamMembershipService{}, &fakeUserService{}, &fakeStackService{}, &fakeWebhookService{}, &fakeResourceControlService{}, &fakeScheduleService{}, &fakeFileService{}, nil, &fakeTelemetryService{}, "")
+	context.StartupGrace = 0
+	return context
+}
+
+type failingRegistryService struct {
+	portainer.RegistryService
+}
+
+func (service *failingRegistryService) Registries() ([]portainer.Registry, error) {
+	return nil, errors.New("unable to read registries")
+}
@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Flatten returns the numeric fields of the telemetry data as a flat map, e.g. to export them
+// to StatsD or InfluxDB. Keys are the dotted snake case path of the field, e.g. Endpoint.PendingEdgeCount
+// is flattened as endpoint.pending_edge_count. Boolean fields are flattened as 0/1, other non-numeric
+// fields (strings, maps and slices) are skipped.
+func (d *TelemetryData) Flatten() map[string]float64 {
+	values := make(map[string]float64)
+	walkNumericFields(reflect.ValueOf(d).Elem(), nil, func(path []string, value reflect.Value) error {
+		values[strings.Join(path, ".")] = numericFieldValue(value)
+		return nil
+	})
+	return values
+}
+
+// walkNumericFields calls visit for each numeric and boolean field of a structure, including the fields
+// of the nested structures, in declaration order. The path holds the snake case names of the field and
+// of its parent structures. Other fields (strings, maps and slices) are skipped.
+func walkNumericFields(value reflect.Value, path []string, visit func(path []string, value reflect.Value) error) error {
+	valueType := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldPath := append(append([]string(nil), path...), toSnakeCase(field.Name))
+		fieldValue := value.Field(i)
+
+		var err error
+		switch fieldValue.Kind() {
+		case reflect.Struct:
+			err = walkNumericFields(fieldValue, fieldPath, visit)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.Bool:
+			err = visit(fieldPath, fieldValue)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// numericFieldValue returns the value of a field visited by walkNumericFields, booleans being 0/1
+func numericFieldValue(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.Bool:
+		if value.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
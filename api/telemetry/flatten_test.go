@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	data := &TelemetryData{
+		Endpoint: EndpointTelemetryData{
+			Count:            3,
+			PendingEdgeCount: 1,
+			AgentPercent:     33.5,
+		},
+		Stack: StackTelemetryData{
+			Count: 4,
+		},
+		Runtime: RuntimeTelemetryData{
+			Version:  "1.24.0",
+			Platform: "linux",
+		},
+		Process: ProcessTelemetryData{
+			HeapAllocBytes: 2048,
+		},
+	}
+
+	values := data.Flatten()
+
+	expected := map[string]float64{
+		"endpoint.count":              3,
+		"endpoint.pending_edge_count": 1,
+		"endpoint.agent_percent":      33.5,
+		"endpoint.docker_count":       0,
+		"stack.count":                 4,
+		"process.heap_alloc_bytes":    2048,
+	}
+	for key, expectedValue := range expected {
+		value, ok := values[key]
+		if !ok {
+			t.Errorf("Expected key %s to be present", key)
+			continue
+		}
+		if value != expectedValue {
+			t.Errorf("Expected %s to be %g, but it was %g instead", key, expectedValue, value)
+		}
+	}
+
+	for _, key := range []string{"runtime.version", "runtime.platform", "telemetry_id", "endpoint.endpoints"} {
+		if _, ok := values[key]; ok {
+			t.Errorf("Expected non-numeric field %s to be skipped", key)
+		}
+	}
+}
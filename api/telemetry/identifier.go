@@ -0,0 +1,116 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// IdentifierSourceGenerated is used when the telemetry identifier is a randomly generated UUID
+	IdentifierSourceGenerated = "generated"
+	// IdentifierSourceMachineID is used when the telemetry identifier is derived from the host machine-id file
+	IdentifierSourceMachineID = "machine-id"
+	// IdentifierSourceHardwareAddr is used when the telemetry identifier is derived from the host network interfaces
+	IdentifierSourceHardwareAddr = "hardware-address"
+
+	defaultMachineIDPath = "/etc/machine-id"
+	identifierHashSalt   = "portainer-telemetry:"
+)
+
+// computeIdentifier retrieves the telemetry identifier from the store, creating it when
+// it does not exist yet. A new identifier is a random UUID persisted in the store: an
+// identifier derived from the host is only used when DeriveIdentifier is set and the
+// generated identifier cannot be stored, as a derived identifier can be shared by several
+// installs (e.g. containers created from the same image share their machine-id file).
+// The raw host characteristics used to derive an identifier are never sent, only a salted
+// hash of them.
+func computeIdentifier(context *TelemetryJobContext, data *TelemetryData) error {
+	configuration, err := context.telemetryService.Configuration()
+	if err != nil && err != portainer.ErrObjectNotFound {
+		return err
+	}
+
+	if configuration == nil {
+		configuration = &portainer.TelemetryConfiguration{}
+	}
+
+	if configuration.TelemetryID == "" {
+		identifier, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+
+		configuration.TelemetryID = identifier.String()
+		configuration.IdentifierSource = IdentifierSourceGenerated
+
+		err = context.telemetryService.UpdateConfiguration(configuration)
+		if err != nil {
+			// A generated identifier that is not stored would change on every run
+			derived, source, ok := deriveIdentifier(context)
+			if !ok {
+				return err
+			}
+
+			context.logf("[WARN] [telemetry] [message: unable to store the telemetry identifier, using an identifier derived from the host] [source: %s] [err: %s]\n", source, err)
+			configuration.TelemetryID = derived
+			configuration.IdentifierSource = source
+		}
+	}
+
+	data.TelemetryID = configuration.TelemetryID
+	data.IdentifierSource = configuration.IdentifierSource
+
+	return nil
+}
+
+// deriveIdentifier returns an identifier derived from the host machine-id file, or from the
+// hardware addresses of the host, and its source. It returns false when DeriveIdentifier is
+// not set or when no host characteristic is available.
+func deriveIdentifier(context *TelemetryJobContext) (string, string, bool) {
+	if !context.DeriveIdentifier {
+		return "", "", false
+	}
+
+	machineID, err := ioutil.ReadFile(context.machineIDPath)
+	if err == nil && strings.TrimSpace(string(machineID)) != "" {
+		return hashIdentifier(strings.TrimSpace(string(machineID))), IdentifierSourceMachineID, true
+	}
+
+	addrs, err := context.hardwareAddrs()
+	if err == nil && len(addrs) > 0 {
+		return hashIdentifier(strings.Join(addrs, ",")), IdentifierSourceHardwareAddr, true
+	}
+
+	return "", "", false
+}
+
+func hashIdentifier(value string) string {
+	hash := sha256.Sum256([]byte(identifierHashSalt + value))
+	return hex.EncodeToString(hash[:])
+}
+
+// hardwareAddrs returns the sorted hardware addresses of the non-loopback network interfaces.
+func hardwareAddrs() ([]string, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0)
+	for _, networkInterface := range interfaces {
+		if networkInterface.Flags&net.FlagLoopback != 0 || len(networkInterface.HardwareAddr) == 0 {
+			continue
+		}
+		addrs = append(addrs, networkInterface.HardwareAddr.String())
+	}
+	sort.Strings(addrs)
+
+	return addrs, nil
+}
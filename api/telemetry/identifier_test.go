@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeIdentifier(t *testing.T) {
+	t.Run("Stored identifier", func(t *testing.T) {
+		telemetryService := &fakeTelemetryService{configuration: &portainer.TelemetryConfiguration{
+			TelemetryID:      "stored-id",
+			IdentifierSource: IdentifierSourceGenerated,
+		}}
+		context := newTestTelemetryJobContext()
+		context.telemetryService = telemetryService
+		context.DeriveIdentifier = true
+
+		data := &TelemetryData{}
+		err := computeIdentifier(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.TelemetryID != "stored-id" {
+			t.Errorf("Expected TelemetryID to be 'stored-id', but it was %s instead", data.TelemetryID)
+		}
+		if data.IdentifierSource != IdentifierSourceGenerated {
+			t.Errorf("Expected IdentifierSource to be %s, but it was %s instead", IdentifierSourceGenerated, data.IdentifierSource)
+		}
+	})
+
+	t.Run("Generated identifier", func(t *testing.T) {
+		machineIDPath := writeTestMachineID(t)
+		defer os.RemoveAll(filepath.Dir(machineIDPath))
+
+		identifiers := make([]string, 0, 2)
+		for i := 0; i < 2; i++ {
+			telemetryService := &fakeTelemetryService{}
+			context := newTestTelemetryJobContext()
+			context.telemetryService = telemetryService
+			context.DeriveIdentifier = true
+			context.machineIDPath = machineIDPath
+
+			data := &TelemetryData{}
+			err := computeIdentifier(context, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.IdentifierSource != IdentifierSourceGenerated {
+				t.Errorf("Expected IdentifierSource to be %s, but it was %s instead", IdentifierSourceGenerated, data.IdentifierSource)
+			}
+			if telemetryService.configuration.TelemetryID != data.TelemetryID {
+				t.Errorf("Expected the generated identifier to be persisted, but %s was stored instead", telemetryService.configuration.TelemetryID)
+			}
+			identifiers = append(identifiers, data.TelemetryID)
+		}
+
+		// Two installs sharing the same machine-id file must not share their identifier
+		if identifiers[0] == identifiers[1] {
+			t.Errorf("Expected the installs to have distinct identifiers, but both were %s", identifiers[0])
+		}
+	})
+
+	t.Run("Derived identifier", func(t *testing.T) {
+		machineIDPath := writeTestMachineID(t)
+		defer os.RemoveAll(filepath.Dir(machineIDPath))
+
+		identifiers := make([]string, 0, 2)
+		for i := 0; i < 2; i++ {
+			context := newTestTelemetryJobContext()
+			context.telemetryService = &fakeTelemetryService{updateErr: errors.New("database is read-only")}
+			context.DeriveIdentifier = true
+			context.machineIDPath = machineIDPath
+
+			data := &TelemetryData{}
+			err := computeIdentifier(context, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data.IdentifierSource != IdentifierSourceMachineID {
+				t.Errorf("Expected IdentifierSource to be %s, but it was %s instead", IdentifierSourceMachineID, data.IdentifierSource)
+			}
+			if strings.Contains(data.TelemetryID, "0123456789abcdef") {
+				t.Errorf("Expected TelemetryID to not contain the raw machine identifier, but it was %s", data.TelemetryID)
+			}
+			identifiers = append(identifiers, data.TelemetryID)
+		}
+
+		// The identifier cannot be stored: the same identifier must be derived on each run
+		if identifiers[0] != identifiers[1] {
+			t.Errorf("Expected the derived identifier to be stable, but got %s and %s", identifiers[0], identifiers[1])
+		}
+	})
+
+	t.Run("Unstored identifier without derivation", func(t *testing.T) {
+		updateErr := errors.New("database is read-only")
+		context := newTestTelemetryJobContext()
+		context.telemetryService = &fakeTelemetryService{updateErr: updateErr}
+
+		err := computeIdentifier(context, &TelemetryData{})
+		if err != updateErr {
+			t.Errorf("Expected the store error to be returned, but got %v", err)
+		}
+	})
+}
+
+func writeTestMachineID(t *testing.T) string {
+	directory, err := ioutil.TempDir("", "telemetry")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machineIDPath := filepath.Join(directory, "machine-id")
+	err = ioutil.WriteFile(machineIDPath, []byte("0123456789abcdef\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return machineIDPath
+}
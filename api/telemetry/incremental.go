@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"reflect"
+)
+
+// ComputeTelemetryIncremental recomputes the given sections of a previously computed telemetry data,
+// e.g. SectionEndpoint, and copies the other sections from previous, which is left untouched.
+// It is meant for frequent local refreshes: the values derived from several sections (e.g. the stacks
+// per registry of the registry section, computed from the stacks) may be stale until every section
+// involved is refreshed. The sections are recomputed in the order of a full computation, a section
+// disabled by the settings is emptied. The store snapshot function of the context is not used.
+// It returns a *SectionError if a section is unknown or cannot be computed.
+func ComputeTelemetryIncremental(context *TelemetryJobContext, previous *TelemetryData, sections ...string) (*TelemetryData, error) {
+	requested := make(map[string]bool)
+	for _, section := range sections {
+		if !isRegisteredSection(section) {
+			return nil, &SectionError{Section: section, Err: errUnknownSection}
+		}
+		requested[section] = true
+	}
+
+	data := *previous
+
+	for _, section := range telemetrySections {
+		if !requested[section.name] {
+			continue
+		}
+
+		resetSection(&data, section.name)
+		if section.skip != nil && section.skip(context, &data) {
+			continue
+		}
+
+		compute := section.compute
+		sectionError := context.computeSection(section.name, func() error {
+			return compute(context, &data)
+		})
+		if sectionError != nil {
+			return nil, sectionError
+		}
+	}
+
+	return &data, nil
+}
+
+func isRegisteredSection(name string) bool {
+	for _, section := range telemetrySections {
+		if section.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resetSection sets the field of the telemetry data holding the section to its zero value.
+// Section names are the snake case names of the TelemetryData fields.
+func resetSection(data *TelemetryData, section string) {
+	value := reflect.ValueOf(data).Elem()
+	valueType := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		if toSnakeCase(valueType.Field(i).Name) == section {
+			value.Field(i).Set(reflect.Zero(valueType.Field(i).Type))
+			return
+		}
+	}
+}
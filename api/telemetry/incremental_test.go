@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeTelemetryIncremental(t *testing.T) {
+	previous := &TelemetryData{
+		TelemetryID: "previous-id",
+		Endpoint:    EndpointTelemetryData{Count: 5, DockerCount: 5},
+		Team:        TeamTelemetryData{Count: 3},
+		Stack:       StackTelemetryData{Count: 7},
+	}
+
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment},
+		{ID: 2, Type: portainer.EdgeAgentEnvironment},
+	}}
+
+	data, err := ComputeTelemetryIncremental(context, previous, SectionEndpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.Count != 2 || data.Endpoint.DockerCount != 1 || data.Endpoint.EdgeCount != 1 {
+		t.Errorf("Expected the endpoint section to be recomputed, but got %+v", data.Endpoint)
+	}
+	if data.TelemetryID != "previous-id" || data.Team.Count != 3 || data.Stack.Count != 7 {
+		t.Errorf("Expected the other sections to be carried over, but got %+v", data)
+	}
+	if previous.Endpoint.Count != 5 {
+		t.Errorf("Expected the previous telemetry data to be left untouched, but got %d endpoints", previous.Endpoint.Count)
+	}
+
+	_, err = ComputeTelemetryIncremental(context, previous, "tag")
+	if sectionError, ok := err.(*SectionError); !ok || sectionError.Err != errUnknownSection {
+		t.Errorf("Expected an unknown section error, but got %v", err)
+	}
+}
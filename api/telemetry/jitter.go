@@ -0,0 +1,13 @@
+package telemetry
+
+import "time"
+
+// jitter returns a random delay between 0 and MaxSendJitter drawn from the random source
+// of the context.
+func (context *TelemetryJobContext) jitter() time.Duration {
+	if context.MaxSendJitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(context.randFloat() * float64(context.MaxSendJitter))
+}
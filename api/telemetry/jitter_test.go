@@ -0,0 +1,38 @@
+package telemetry
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	t.Run("Disabled jitter", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+
+		if jitter := context.jitter(); jitter != 0 {
+			t.Errorf("Expected jitter to be 0, but it was %s instead", jitter)
+		}
+	})
+
+	t.Run("Fixed random value", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.MaxSendJitter = 10 * time.Minute
+		context.randFloat = func() float64 { return 0.25 }
+
+		if jitter := context.jitter(); jitter != 150*time.Second {
+			t.Errorf("Expected jitter to be 2m30s, but it was %s instead", jitter)
+		}
+	})
+
+	t.Run("Seeded random source", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.MaxSendJitter = time.Hour
+		context.randFloat = rand.New(rand.NewSource(42)).Float64
+
+		expected := time.Duration(rand.New(rand.NewSource(42)).Float64() * float64(time.Hour))
+		if jitter := context.jitter(); jitter != expected {
+			t.Errorf("Expected jitter to be %s, but it was %s instead", expected, jitter)
+		}
+	})
+}
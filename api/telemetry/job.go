@@ -0,0 +1,516 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer/api"
+	"golang.org/x/time/rate"
+)
+
+// TelemetryJobRunner is used to run a TelemetryJob
+type TelemetryJobRunner struct {
+	schedule *portainer.Schedule
+	context  *TelemetryJobContext
+}
+
+// TelemetryJobContext represents the context of execution of a TelemetryJob
+type TelemetryJobContext struct {
+	endpointService        portainer.EndpointService
+	registryService        portainer.RegistryService
+	settingsService        portainer.SettingsService
+	teamService            portainer.TeamService
+	teamMembershipService  portainer.TeamMembershipService
+	userService            portainer.UserService
+	stackService           portainer.StackService
+	webhookService         portainer.WebhookService
+	resourceControlService portainer.ResourceControlService
+	scheduleService        portainer.ScheduleService
+	fileService            portainer.FileService
+	reverseTunnelService   portainer.ReverseTunnelService
+	telemetryService       portainer.TelemetryService
+	telemetryURL           string
+	platform               string
+	arch                   string
+	machineIDPath          string
+	hardwareAddrs          func() ([]string, error)
+	now                    func() time.Time
+	randFloat              func() float64
+	lastPayload            *payloadCapture
+	sendClients            *sendClientCache
+	composeFiles           *composeFileCache
+	startTime              time.Time
+	registryProbeTimeout   time.Duration
+
+	// DeriveIdentifier enables the derivation of the telemetry identifier from durable
+	// host characteristics when no identifier is stored and the generated identifier
+	// cannot be stored either, so that such an install keeps the same identifier across runs.
+	DeriveIdentifier bool
+
+	// BatchSize is the number of runs accumulated before sending. When greater than 1,
+	// the telemetry data of each run is buffered in the database and the whole buffer
+	// is sent as a JSON array once it holds BatchSize entries.
+	BatchSize int
+
+	// MaxPendingBatch is the maximum number of entries kept in the batch buffered in the database,
+	// the oldest entries are dropped beyond it so that the buffer does not grow without limit while
+	// the telemetry URL is unreachable. It is never lower than BatchSize. Defaults to 90, disabled when 0.
+	MaxPendingBatch int
+
+	// AuthenticationDisabled must be set when Portainer runs without authentication (--no-auth),
+	// in which case the instance is publicly accessible.
+	AuthenticationDisabled bool
+
+	// CompressPayload enables the gzip compression of the payload sent to the telemetry URL.
+	CompressPayload bool
+
+	// MaxSendJitter is the upper bound of the random delay applied before sending the data,
+	// used to spread the submissions of instances sharing the same schedule. Disabled when 0.
+	MaxSendJitter time.Duration
+
+	// MinimumAgentVersion is the version below which the agent of an endpoint is reported as outdated.
+	// Defaults to 1.5.1.
+	MinimumAgentVersion string
+
+	// ComputeStacksPerRegistry enables the computation of the number of stacks pulling images
+	// from each type of registry. It is disabled by default as it reads the file of every stack.
+	ComputeStacksPerRegistry bool
+
+	// ComputeUnusedRegistries enables the computation of the number of registries that no stack pulls
+	// images from. It is disabled by default as it reads the file of every stack.
+	ComputeUnusedRegistries bool
+
+	// MinSendInterval is the minimum interval between two telemetry sends, whatever the schedule
+	// of the job. The data is still computed but not sent when the last submission is too recent.
+	// Defaults to 24 hours, disabled when 0.
+	MinSendInterval time.Duration
+
+	// PrivacyMode suppresses the per-endpoint entries of the endpoint section: Endpoint.Endpoints
+	// is always sent as an empty array. The endpoint count and the totals rolled up from the
+	// endpoints (counts by type and status, cloud provider distribution) are still sent.
+	PrivacyMode bool
+
+	// LargeStackServiceThreshold is the number of services above which a stack is reported as large.
+	// Defaults to 20.
+	LargeStackServiceThreshold int
+
+	// StoreReadTimeout is the maximum duration of a read against the store. A section is skipped
+	// and left empty when one of its reads exceeds this duration, so that a database held by a long
+	// write transaction (e.g. a backup) cannot stall the job. Disabled when 0.
+	StoreReadTimeout time.Duration
+
+	// TunnelServerAddress must be set to the address the tunnel server listens on (--tunnel-addr).
+	// Only whether a specific address is configured is reported.
+	TunnelServerAddress string
+
+	// Format is the encoding of the payload sent to the telemetry URL, PayloadFormatJSON (default)
+	// or PayloadFormatProtobuf. Batches are always sent as JSON.
+	Format string
+
+	// CapturePayload keeps a copy of the bytes sent during the most recent send, available
+	// through LastPayload. Disabled by default to avoid keeping the payload in memory.
+	CapturePayload bool
+
+	// EndpointSampleRate is the probability (0-1) for an endpoint to be included in the per-endpoint
+	// entries of the endpoint section. The aggregated counts always include every endpoint.
+	// Defaults to 1, every endpoint is included.
+	EndpointSampleRate float64
+
+	// ComputeDanglingUserGrants enables the computation of the number of resource control accesses
+	// granted to users that no longer exist. Disabled by default as every access is cross-referenced
+	// with the users.
+	ComputeDanglingUserGrants bool
+
+	// ComputeResourceControlCoverage enables the computation of the fraction of the containers, services,
+	// volumes and stacks of the endpoint snapshots that have a resource control. Disabled by default as
+	// the resource controls are cross-referenced with the snapshots of every endpoint.
+	ComputeResourceControlCoverage bool
+
+	// StartupGrace is the duration after the creation of the context during which the data is
+	// computed but not sent, as the snapshots can be missing or stale right after startup.
+	// Defaults to 10 minutes, disabled when 0.
+	StartupGrace time.Duration
+
+	// RetryBudget is the maximum number of retries of a run, shared by the computation of the sections
+	// and the send: a failing section or send is retried until the budget is exhausted. Batched sends
+	// are not retried. Disabled when 0.
+	RetryBudget int
+
+	// PostSend, when set, is called after every send attempt with the result of the run,
+	// whether the data was sent or not.
+	PostSend func(result *TelemetrySendResult)
+
+	// MetricsRecorder is called at the end of every run with the result of the run and the duration
+	// of its steps. No metrics are recorded by default.
+	MetricsRecorder MetricsRecorder
+
+	// LogWriter is the destination of the telemetry logs, e.g. a dedicated file.
+	// The logs are written to the standard logger when not set (default).
+	LogWriter io.Writer
+
+	// CanaryURL is the URL of a canary telemetry server receiving the data of CanaryPercent percent
+	// of the installations, to roll out server-side changes progressively. The installations are selected
+	// using a hash of their identifier so that each installation always reports to the same server.
+	// The canary is disabled when CanaryURL is empty or CanaryPercent is 0 (default).
+	CanaryURL     string
+	CanaryPercent int
+
+	// CorrelationID identifies the request that triggered an on-demand run. When set, it is appended
+	// to every log line and sent in the X-Correlation-Id header. Empty for scheduled runs (default).
+	CorrelationID string
+
+	// LogPayloadOnError logs the payload as indented JSON when it cannot be sent.
+	LogPayloadOnError bool
+
+	// HashSensitive adds a hash of the URL of each registry to the registry configurations, the
+	// telemetry server can count distinct registries without learning their addresses.
+	// The URLs are never sent in plain text.
+	HashSensitive bool
+
+	// ProbeRegistries enables the probing of every registry to report how many of them are reachable.
+	// Disabled by default as a request is sent to each registry.
+	ProbeRegistries bool
+
+	// SchemaVersion is the version of the telemetry data schema supported by the telemetry server.
+	// The fields introduced after that version are not sent. Every field is sent when 0 (default).
+	SchemaVersion int
+
+	// SendDisabled computes the telemetry data without sending it, e.g. to only persist it locally
+	// when the telemetry is opted out.
+	SendDisabled bool
+
+	// PersistLocally stores the latest telemetry data in the database on each run, whether it is sent
+	// or not. The stored data is available through PersistedPayload.
+	PersistLocally bool
+
+	// MaxPayloadBytes is the maximum size of the encoded payload, before compression. The optional parts
+	// of the data (the endpoint entries, the registry configurations, then the distributions) are removed
+	// until the payload fits, and are listed in SheddedSections. Unlimited when 0 (default).
+	MaxPayloadBytes int
+
+	// AggregationWindow, when greater than 1, sends the average of the last AggregationWindow computed
+	// payloads instead of the latest one, to smooth the reported values. The payloads of the window are
+	// stored in the database so that they are kept across restarts. Disabled by default.
+	AggregationWindow int
+
+	// RateLimiter, when set, limits the number of endpoints and resource controls processed per second
+	// to lower the CPU usage of the computation on constrained hosts. Unlimited by default.
+	RateLimiter *rate.Limiter
+
+	// IncludeProcessStats enables the process section: the heap size, number of goroutines and
+	// garbage collections of the Portainer process and its uptime in seconds. Disabled by default.
+	IncludeProcessStats bool
+
+	// DeploymentLabel is a free-form label (e.g. "prod-eu") included in the telemetry data
+	// to identify the deployment. It is never populated automatically and is omitted when empty.
+	DeploymentLabel string
+
+	// SnapshotStore, when set, is used to retrieve a read-only snapshot of the store at the
+	// beginning of the computation. All the sections are then computed against that snapshot,
+	// which guarantees a point-in-time consistent payload: writes happening while the telemetry
+	// is computed are not taken into account.
+	SnapshotStore func() (*StoreSnapshot, error)
+}
+
+// StoreSnapshot represents a point-in-time copy of the services read by the telemetry job
+type StoreSnapshot struct {
+	EndpointService        portainer.EndpointService
+	RegistryService        portainer.RegistryService
+	SettingsService        portainer.SettingsService
+	TeamService            portainer.TeamService
+	TeamMembershipService  portainer.TeamMembershipService
+	UserService            portainer.UserService
+	StackService           portainer.StackService
+	WebhookService         portainer.WebhookService
+	ResourceControlService portainer.ResourceControlService
+	ScheduleService        portainer.ScheduleService
+	Close                  func() error
+}
+
+// NewTelemetryJobContext returns a new context that can be used to execute a TelemetryJob
+func NewTelemetryJobContext(endpointService portainer.EndpointService, registryService portainer.RegistryService, settingsService portainer.SettingsService, teamService portainer.TeamService, teamMembershipService portainer.TeamMembershipService, userService portainer.UserService, stackService portainer.StackService, webhookService portainer.WebhookService, resourceControlService portainer.ResourceControlService, scheduleService portainer.ScheduleService, fileService portainer.FileService, reverseTunnelService portainer.ReverseTunnelService, telemetryService portainer.TelemetryService, telemetryURL string) *TelemetryJobContext {
+	context := &TelemetryJobContext{
+		endpointService:            endpointService,
+		registryService:            registryService,
+		settingsService:            settingsService,
+		teamService:                teamService,
+		teamMembershipService:      teamMembershipService,
+		userService:                userService,
+		stackService:               stackService,
+		webhookService:             webhookService,
+		resourceControlService:     resourceControlService,
+		scheduleService:            scheduleService,
+		fileService:                fileService,
+		reverseTunnelService:       reverseTunnelService,
+		telemetryService:           telemetryService,
+		telemetryURL:               telemetryURL,
+		platform:                   runtime.GOOS,
+		arch:                       runtime.GOARCH,
+		machineIDPath:              defaultMachineIDPath,
+		hardwareAddrs:              hardwareAddrs,
+		now:                        time.Now,
+		randFloat:                  rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		lastPayload:                &payloadCapture{},
+		sendClients:                &sendClientCache{},
+		registryProbeTimeout:       defaultRegistryProbeTimeout,
+		MinimumAgentVersion:        defaultMinimumAgentVersion,
+		MetricsRecorder:            noopMetricsRecorder{},
+		MinSendInterval:            defaultMinSendInterval,
+		MaxPendingBatch:            defaultMaxPendingBatch,
+		LargeStackServiceThreshold: defaultLargeStackServiceThreshold,
+		Format:                     PayloadFormatJSON,
+		EndpointSampleRate:         1,
+		StartupGrace:               defaultStartupGrace,
+	}
+	context.startTime = context.now()
+
+	return context
+}
+
+// NewTelemetryJobRunner returns a new runner that can be scheduled
+func NewTelemetryJobRunner(schedule *portainer.Schedule, context *TelemetryJobContext) *TelemetryJobRunner {
+	return &TelemetryJobRunner{
+		schedule: schedule,
+		context:  context,
+	}
+}
+
+// GetSchedule returns the schedule associated to the runner
+func (runner *TelemetryJobRunner) GetSchedule() *portainer.Schedule {
+	return runner.schedule
+}
+
+// Run triggers the execution of the schedule.
+// It computes the telemetry data from the content of the database and
+// sends it to the telemetry URL.
+func (runner *TelemetryJobRunner) Run() {
+	go runner.RunWithResult()
+}
+
+// RunWithResult computes and sends the telemetry data and returns the outcome of the run.
+// A failure while computing a section is reported in the result and does not prevent
+// the data of the other sections from being sent.
+func (runner *TelemetryJobRunner) RunWithResult() *TelemetrySendResult {
+	result := &TelemetrySendResult{}
+	timings := make(map[string]time.Duration)
+	defer runner.recordRun(result, timings, time.Now())
+	defer runner.updateLastRun(result, runner.context.now())
+
+	budget := newRetryBudget(runner.context.RetryBudget)
+	defer func() {
+		result.RetryBudgetRemaining = budget.remaining
+	}()
+
+	computeStart := time.Now()
+	data, sectionErrors, err := computeTelemetry(runner.context, budget)
+	timings[TimingCompute] = time.Since(computeStart)
+	result.SectionErrors = sectionErrors
+	for _, sectionError := range sectionErrors {
+		runner.context.logf("background schedule error (telemetry). Unable to compute telemetry section (section=%s) (err=%s)\n", sectionError.Section, sectionError.Err)
+	}
+	if err != nil {
+		runner.context.logf("background schedule error (telemetry). Unable to compute telemetry data (err=%s)\n", err)
+		result.Err = err
+		return result
+	}
+
+	if emptySections := data.EmptySections(); len(emptySections) > 0 {
+		runner.context.logf("[DEBUG] [telemetry] [message: telemetry sections are empty] [sections: %s]\n", strings.Join(emptySections, ","))
+	}
+
+	if runner.context.PersistLocally {
+		err = runner.context.persistLocally(data)
+		if err != nil {
+			runner.context.logf("background schedule error (telemetry). Unable to persist telemetry data locally (err=%s)\n", err)
+		}
+	}
+
+	if runner.context.AggregationWindow > 1 {
+		aggregated, err := runner.context.aggregateWindow(data)
+		if err != nil {
+			runner.context.logf("background schedule error (telemetry). Unable to aggregate telemetry data, sending the latest data (err=%s)\n", err)
+		} else {
+			data = aggregated
+		}
+	}
+
+	if runner.context.SendDisabled {
+		return result
+	}
+
+	if runner.context.withinStartupGrace() {
+		runner.context.logf("[INFO] [telemetry] [message: telemetry send skipped during the startup grace period] [startup_grace: %s]\n", runner.context.StartupGrace)
+		result.WithinStartupGrace = true
+		return result
+	}
+
+	if runner.context.BatchSize <= 1 {
+		configuration, err := runner.context.telemetryService.Configuration()
+		if err != nil && err != portainer.ErrObjectNotFound {
+			runner.context.logf("background schedule error (telemetry). Unable to retrieve telemetry configuration (err=%s)\n", err)
+			result.Err = err
+			return result
+		}
+
+		if runner.context.sendThrottled(configuration) {
+			runner.context.logf("[INFO] [telemetry] [message: telemetry send throttled, last submission is too recent] [min_send_interval: %s]\n", runner.context.MinSendInterval)
+			result.Throttled = true
+			return result
+		}
+	}
+
+	if jitter := runner.context.jitter(); jitter > 0 {
+		time.Sleep(jitter)
+	}
+
+	data, err = runner.context.shedPayload(data)
+	if err != nil {
+		runner.context.logf("background schedule error (telemetry). Unable to encode telemetry data (err=%s)\n", err)
+		result.Err = err
+		return result
+	}
+
+	sendStart := time.Now()
+	defer func() {
+		timings[TimingSend] = time.Since(sendStart)
+	}()
+
+	if runner.context.BatchSize > 1 {
+		result.Sent, err = runner.context.batchTelemetry(data, result)
+		if err != nil {
+			runner.context.logf("background schedule error (telemetry). Unable to send telemetry batch (err=%s)\n", err)
+			result.Err = err
+		}
+		return result
+	}
+
+	err = runner.context.sendTelemetryWithRetries(budget, data, result)
+	if err != nil {
+		runner.context.logf("background schedule error (telemetry). Unable to send telemetry data (err=%s)\n", err)
+		result.Err = err
+		return result
+	}
+
+	result.Sent = true
+	return result
+}
+
+// updateLastRun persists the outcome of a run. The run is stamped with its start time: stamping it with
+// the end time would make the next run of a schedule matching MinSendInterval (e.g. @every 24h) start
+// slightly less than MinSendInterval after the last submission, and be throttled.
+func (runner *TelemetryJobRunner) updateLastRun(result *TelemetrySendResult, start time.Time) {
+	// A run that does not attempt to send is not counted as a failure
+	state := portainer.TelemetryRunState{
+		Time:      start.Unix(),
+		Sent:      result.Sent,
+		Throttled: result.Throttled || result.WithinStartupGrace || runner.context.SendDisabled,
+	}
+
+	err := runner.context.telemetryService.UpdateLastRun(state)
+	if err != nil {
+		runner.context.logf("background schedule error (telemetry). Unable to persist telemetry run state (err=%s)\n", err)
+	}
+}
+
+// ComputeTelemetry computes the telemetry data using the services available in the context.
+// When a store snapshot function is configured, the data is read from a snapshot of the store.
+// It returns a *SectionError if any of the sections cannot be computed.
+func ComputeTelemetry(context *TelemetryJobContext) (*TelemetryData, error) {
+	data, sectionErrors, err := computeTelemetry(context, newRetryBudget(context.RetryBudget))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sectionErrors) > 0 {
+		return nil, sectionErrors[0]
+	}
+
+	return data, nil
+}
+
+// computeTelemetry computes every section of the telemetry data, collecting the errors
+// of the sections that cannot be computed. An error is only returned when the data
+// cannot be computed at all.
+func computeTelemetry(context *TelemetryJobContext, budget *retryBudget) (*TelemetryData, []*SectionError, error) {
+	if context.SnapshotStore != nil {
+		snapshot, err := context.SnapshotStore()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer snapshot.Close()
+
+		snapshotContext := *context
+		snapshotContext.endpointService = snapshot.EndpointService
+		snapshotContext.registryService = snapshot.RegistryService
+		snapshotContext.settingsService = snapshot.SettingsService
+		snapshotContext.teamService = snapshot.TeamService
+		snapshotContext.teamMembershipService = snapshot.TeamMembershipService
+		snapshotContext.userService = snapshot.UserService
+		snapshotContext.stackService = snapshot.StackService
+		snapshotContext.webhookService = snapshot.WebhookService
+		snapshotContext.resourceControlService = snapshot.ResourceControlService
+		snapshotContext.scheduleService = snapshot.ScheduleService
+		context = &snapshotContext
+	}
+
+	// The Compose files of the stacks are parsed once per run, whatever the number of sections using them
+	runContext := *context
+	runContext.composeFiles = &composeFileCache{}
+	context = &runContext
+
+	data := &TelemetryData{
+		DeploymentLabel: context.DeploymentLabel,
+	}
+
+	sectionError := context.computeSectionWithRetries(budget, data, SectionIdentifier, func() error {
+		return computeIdentifier(context, data)
+	})
+	if sectionError != nil {
+		return nil, nil, sectionError
+	}
+
+	sectionErrors := make([]*SectionError, 0)
+	for _, section := range telemetrySections {
+		if section.skip != nil && section.skip(context, data) {
+			continue
+		}
+
+		compute := section.compute
+		sectionError := context.computeSectionWithRetries(budget, data, section.name, func() error {
+			return compute(context, data)
+		})
+		if sectionError == nil {
+			continue
+		}
+
+		if sectionError.Err == errStoreReadTimeout {
+			context.logf("[WARN] [telemetry] [message: telemetry section skipped, store read timeout exceeded] [section: %s] [timeout: %s]\n", section.name, context.StoreReadTimeout)
+		}
+		sectionErrors = append(sectionErrors, sectionError)
+	}
+
+	return data, sectionErrors, nil
+}
+
+// computeSection executes the compute function of a section and converts both the returned error
+// and a panic into a *SectionError, so that corrupt data in the database cannot crash the process.
+func (context *TelemetryJobContext) computeSection(section string, compute func() error) (sectionError *SectionError) {
+	defer func() {
+		if r := recover(); r != nil {
+			context.logf("[ERROR] [telemetry] [message: recovered from panic while computing telemetry section] [section: %s] [panic: %v]\n%s", section, r, debug.Stack())
+			sectionError = &SectionError{Section: section, Err: fmt.Errorf("panic: %v", r)}
+		}
+	}()
+
+	err := compute()
+	if err != nil {
+		return &SectionError{Section: section, Err: err}
+	}
+
+	return nil
+}
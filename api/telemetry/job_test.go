@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt"
+	"github.com/portainer/portainer/api/filesystem"
+)
+
+func newTestStore(t *testing.T) (*bolt.Store, func()) {
+	directory, err := ioutil.TempDir("", "telemetry-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileService, err := filesystem.NewService(directory, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := bolt.NewStore(directory, fileService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store, func() {
+		store.Close()
+		os.RemoveAll(directory)
+	}
+}
+
+func TestComputeTelemetryFromStoreSnapshot(t *testing.T) {
+	store, teardown := newTestStore(t)
+	defer teardown()
+
+	err := store.EndpointService.CreateEndpoint(&portainer.Endpoint{ID: 1, Type: portainer.DockerEnvironment})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.SettingsService.UpdateSettings(&portainer.Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context := NewTelemetryJobContext(store.EndpointService, store.RegistryService, store.SettingsService, store.TeamService, store.TeamMembershipService, store.UserService, store.StackService, store.WebhookService, store.ResourceControlService, store.ScheduleService, nil, nil, store.TelemetryService, "")
+	context.SnapshotStore = func() (*StoreSnapshot, error) {
+		directory, err := ioutil.TempDir("", "telemetry-snapshot")
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot, err := store.Snapshot(directory)
+		if err != nil {
+			return nil, err
+		}
+
+		// Simulates a write happening while the telemetry is computed
+		err = store.EndpointService.CreateEndpoint(&portainer.Endpoint{ID: 2, Type: portainer.DockerEnvironment})
+		if err != nil {
+			return nil, err
+		}
+
+		return &StoreSnapshot{
+			EndpointService:        snapshot.EndpointService,
+			RegistryService:        snapshot.RegistryService,
+			SettingsService:        snapshot.SettingsService,
+			TeamService:            snapshot.TeamService,
+			TeamMembershipService:  snapshot.TeamMembershipService,
+			UserService:            snapshot.UserService,
+			ResourceControlService: snapshot.ResourceControlService,
+			ScheduleService:        snapshot.ScheduleService,
+			WebhookService:         snapshot.WebhookService,
+			StackService:           snapshot.StackService,
+			Close: func() error {
+				defer os.RemoveAll(directory)
+				return snapshot.Close()
+			},
+		}, nil
+	}
+
+	data, err := ComputeTelemetry(context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.Count != 1 {
+		t.Errorf("Expected the endpoint created after the snapshot to be ignored, but Count was %d", data.Endpoint.Count)
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Errorf("Expected the live store to contain 2 endpoints, but it contained %d", len(endpoints))
+	}
+}
+
+func TestDeploymentLabel(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var envelope struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&envelope)
+		payload = envelope.Data
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.MinSendInterval = 0
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+	if !result.Sent {
+		t.Fatalf("Expected the telemetry to be sent, but it was not (err=%v)", result.Err)
+	}
+	if _, ok := payload["DeploymentLabel"]; ok {
+		t.Errorf("Expected the deployment label to be omitted when empty, but it was sent")
+	}
+
+	context.DeploymentLabel = "prod-eu"
+	result = runner.RunWithResult()
+	if !result.Sent {
+		t.Fatalf("Expected the telemetry to be sent, but it was not (err=%v)", result.Err)
+	}
+	if label := payload["DeploymentLabel"]; label != "prod-eu" {
+		t.Errorf("Expected the deployment label to be prod-eu, but it was %v instead", label)
+	}
+}
@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"encoding/json"
+
+	"github.com/portainer/portainer/api"
+)
+
+// persistLocally stores the telemetry data in the telemetry configuration. The data is stored
+// in full, independently of the schema version supported by the telemetry server.
+func (context *TelemetryJobContext) persistLocally(data *TelemetryData) error {
+	configuration, err := context.telemetryService.Configuration()
+	if err == portainer.ErrObjectNotFound {
+		configuration = &portainer.TelemetryConfiguration{}
+	} else if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	configuration.LocalPayload = payload
+
+	return context.telemetryService.UpdateConfiguration(configuration)
+}
+
+// PersistedPayload returns the telemetry data stored by the latest run when PersistLocally is enabled.
+// It returns nil when no data was stored yet.
+func (context *TelemetryJobContext) PersistedPayload() (*TelemetryData, error) {
+	configuration, err := context.telemetryService.Configuration()
+	if err == portainer.ErrObjectNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(configuration.LocalPayload) == 0 {
+		return nil, nil
+	}
+
+	var data TelemetryData
+	err = json.Unmarshal(configuration.LocalPayload, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
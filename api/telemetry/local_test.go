@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestPersistLocallyWithSendDisabled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{{ID: 1, Type: portainer.DockerEnvironment}}}
+	context.SendDisabled = true
+	context.PersistLocally = true
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+	if result.Sent || requests != 0 {
+		t.Errorf("Expected the telemetry not to be sent, but %d requests were sent", requests)
+	}
+
+	data, err := context.PersistedPayload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == nil {
+		t.Fatal("Expected the telemetry data to be persisted locally")
+	}
+	if data.Endpoint.Count != 1 {
+		t.Errorf("Expected the persisted data to report 1 endpoint, but got %d", data.Endpoint.Count)
+	}
+
+	configuration, _ := context.telemetryService.Configuration()
+	if configuration.ConsecutiveFailures != 0 {
+		t.Errorf("Expected the run not to be counted as a failure, but got %d consecutive failures", configuration.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,22 @@
+package telemetry
+
+import (
+	"log"
+	"strings"
+)
+
+// logf writes a log entry to the LogWriter of the context, or to the standard logger when not set.
+// The correlation ID of the context is appended to the entry when set.
+func (context *TelemetryJobContext) logf(format string, v ...interface{}) {
+	if context.CorrelationID != "" {
+		format = strings.TrimSuffix(format, "\n") + " [correlation_id: %s]\n"
+		v = append(v, context.CorrelationID)
+	}
+
+	if context.LogWriter == nil {
+		log.Printf(format, v...)
+		return
+	}
+
+	log.New(context.LogWriter, "", log.LstdFlags).Printf(format, v...)
+}
@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.LogWriter = &buffer
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+	if result.Err == nil {
+		t.Fatal("Expected the send to fail")
+	}
+
+	if !strings.Contains(buffer.String(), "Unable to send telemetry data") {
+		t.Errorf("Expected the send failure to be logged to the log writer, but got %q", buffer.String())
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Correlation-Id")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.LogWriter = &buffer
+	context.CorrelationID = "req-1234"
+	runner := NewTelemetryJobRunner(nil, context)
+
+	runner.RunWithResult()
+
+	if header != "req-1234" {
+		t.Errorf("Expected the X-Correlation-Id header to be req-1234, but got %q", header)
+	}
+	if !strings.Contains(buffer.String(), "[correlation_id: req-1234]\n") {
+		t.Errorf("Expected the correlation ID to be logged, but got %q", buffer.String())
+	}
+}
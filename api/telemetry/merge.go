@@ -0,0 +1,205 @@
+package telemetry
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MergeTelemetry combines the telemetry data computed by several Portainer instances into a
+// single view. The fields are merged as follows:
+//
+//   - the identifier of the first part is used as the representative identifier
+//   - numeric counts are summed, a negative count (data not tracked) in any part yields -1
+//   - the fields tagged merge:"max" (e.g. an age or a maximum) keep the greatest value, a negative
+//     value in any part yields -1
+//   - booleans are true when true in any part
+//   - strings (e.g. the runtime version) are merged into the comma separated list of the distinct values
+//   - maps are merged by summing the values of each key
+//   - slices are concatenated, duplicate entries are only removed from the fields tagged merge:"set"
+//   - percentages and averages are recomputed from the merged values
+//   - the resource control coverage is dropped, as it cannot be recomputed without the resource counts
+//
+// Nil parts are ignored.
+func MergeTelemetry(parts ...*TelemetryData) *TelemetryData {
+	merged := &TelemetryData{}
+
+	var representative *TelemetryData
+	var teamMembers float64
+	var sampledEndpoints float64
+	var snapshotSuccesses float64
+	var trackedSnapshotEndpoints int
+
+	for _, part := range parts {
+		if part == nil {
+			continue
+		}
+
+		mergeValue(reflect.ValueOf(merged).Elem(), reflect.ValueOf(part).Elem())
+
+		if representative == nil {
+			representative = part
+		}
+
+		teamMembers += part.Team.AverageTeamSize * float64(part.Team.Count)
+		sampledEndpoints += part.Endpoint.SampleRate * float64(part.Endpoint.Count)
+		if part.Endpoint.AverageSnapshotSuccessRate >= 0 {
+			snapshotSuccesses += part.Endpoint.AverageSnapshotSuccessRate * float64(part.Endpoint.Count)
+			trackedSnapshotEndpoints += part.Endpoint.Count
+		}
+	}
+
+	if representative != nil {
+		merged.TelemetryID = representative.TelemetryID
+		merged.IdentifierSource = representative.IdentifierSource
+	}
+
+	merged.Endpoint.AgentPercent = percentage(merged.Endpoint.AgentCount, merged.Endpoint.Count)
+	merged.Endpoint.EdgePercent = percentage(merged.Endpoint.EdgeCount, merged.Endpoint.Count)
+	merged.Endpoint.DirectPercent = percentage(merged.Endpoint.DockerCount, merged.Endpoint.Count)
+
+	merged.Endpoint.SampleRate = 0
+	if merged.Endpoint.Count > 0 {
+		merged.Endpoint.SampleRate = sampledEndpoints / float64(merged.Endpoint.Count)
+	}
+
+	merged.Endpoint.AverageSnapshotSuccessRate = snapshotSuccessRateNotTracked
+	if trackedSnapshotEndpoints > 0 {
+		merged.Endpoint.AverageSnapshotSuccessRate = snapshotSuccesses / float64(trackedSnapshotEndpoints)
+	}
+
+	merged.ResourceControl.PerTypeCoverage = nil
+
+	merged.Team.AverageTeamSize = 0
+	if merged.Team.Count > 0 {
+		merged.Team.AverageTeamSize = teamMembers / float64(merged.Team.Count)
+	}
+
+	return merged
+}
+
+func mergeValue(target, value reflect.Value) {
+	switch target.Kind() {
+	case reflect.Struct:
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			switch field.Tag.Get("merge") {
+			case "max":
+				mergeMax(target.Field(i), value.Field(i))
+			case "set":
+				mergeSet(target.Field(i), value.Field(i))
+			default:
+				mergeValue(target.Field(i), value.Field(i))
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if target.Int() < 0 || value.Int() < 0 {
+			target.SetInt(-1)
+			return
+		}
+		target.SetInt(target.Int() + value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		target.SetUint(target.Uint() + value.Uint())
+	case reflect.Float32, reflect.Float64:
+		target.SetFloat(target.Float() + value.Float())
+	case reflect.Bool:
+		target.SetBool(target.Bool() || value.Bool())
+	case reflect.String:
+		target.SetString(mergeStrings(target.String(), value.String()))
+	case reflect.Map:
+		mergeMap(target, value)
+	case reflect.Slice:
+		mergeSlice(target, value)
+	}
+}
+
+// mergeStrings returns the sorted, comma separated list of the distinct non-empty values of both strings
+func mergeStrings(target, value string) string {
+	if value == "" || target == value {
+		return target
+	}
+	if target == "" {
+		return value
+	}
+
+	values := strings.Split(target, ",")
+	for _, existing := range values {
+		if existing == value {
+			return target
+		}
+	}
+
+	values = append(values, value)
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func mergeMap(target, value reflect.Value) {
+	if value.Len() == 0 {
+		return
+	}
+	if target.IsNil() {
+		target.Set(reflect.MakeMap(target.Type()))
+	}
+
+	for _, key := range value.MapKeys() {
+		existing := target.MapIndex(key)
+		if !existing.IsValid() {
+			target.SetMapIndex(key, value.MapIndex(key))
+			continue
+		}
+
+		entry := reflect.New(target.Type().Elem()).Elem()
+		entry.Set(existing)
+		mergeValue(entry, value.MapIndex(key))
+		target.SetMapIndex(key, entry)
+	}
+}
+
+// mergeMax keeps the greatest of two integers, or -1 when any of them is negative (data not tracked)
+func mergeMax(target, value reflect.Value) {
+	if target.Int() < 0 || value.Int() < 0 {
+		target.SetInt(-1)
+		return
+	}
+	if value.Int() > target.Int() {
+		target.SetInt(value.Int())
+	}
+}
+
+// mergeSlice concatenates the entries of both slices. The entries of the per-entity slices
+// (e.g. the endpoints) are kept even when identical, as each of them counts.
+func mergeSlice(target, value reflect.Value) {
+	if target.IsNil() && !value.IsNil() {
+		target.Set(reflect.MakeSlice(target.Type(), 0, value.Len()))
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		target.Set(reflect.Append(target, value.Index(i)))
+	}
+}
+
+// mergeSet appends the entries of value that are not already part of target
+func mergeSet(target, value reflect.Value) {
+	if target.IsNil() && !value.IsNil() {
+		target.Set(reflect.MakeSlice(target.Type(), 0, value.Len()))
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		duplicate := false
+		for j := 0; j < target.Len(); j++ {
+			if reflect.DeepEqual(target.Index(j).Interface(), value.Index(i).Interface()) {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			target.Set(reflect.Append(target, value.Index(i)))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestMergeTelemetry(t *testing.T) {
+	first := &TelemetryData{
+		TelemetryID: "first",
+		Endpoint: EndpointTelemetryData{
+			Count:                      2,
+			AgentCount:                 1,
+			DockerCount:                1,
+			CloudProviderDistribution:  map[string]int{"aws": 2},
+			Endpoints:                  []EndpointEnvironmentTelemetryData{{Type: EndpointTypeAgent, CloudProvider: "aws"}},
+			SampleRate:                 1,
+			AverageSnapshotSuccessRate: 0.5,
+		},
+		Runtime:         RuntimeTelemetryData{Version: "1.24.0"},
+		ResourceControl: ResourceControlTelemetryData{PerTypeCoverage: map[string]float64{ResourceControlTypeContainer: 0.5}},
+		Team:            TeamTelemetryData{Count: 1, AverageTeamSize: 4, MaxTeamSize: 4},
+		Webhook:         WebhookTelemetryData{Count: 1, TriggeredSinceLastRun: webhookTriggersNotTracked},
+	}
+	second := &TelemetryData{
+		TelemetryID: "second",
+		Endpoint: EndpointTelemetryData{
+			Count:                      2,
+			AgentCount:                 2,
+			CloudProviderDistribution:  map[string]int{"aws": 1, "azure": 1},
+			Endpoints:                  []EndpointEnvironmentTelemetryData{{Type: EndpointTypeAgent, CloudProvider: "aws"}, {Type: EndpointTypeAgent, CloudProvider: "azure"}},
+			SampleRate:                 1,
+			AverageSnapshotSuccessRate: snapshotSuccessRateNotTracked,
+		},
+		Runtime:  RuntimeTelemetryData{Version: "1.23.0"},
+		Settings: SettingsTelemetryData{PublicAccessEnabled: true},
+		Team:     TeamTelemetryData{Count: 3, AverageTeamSize: 2, MaxTeamSize: 3},
+		Webhook:  WebhookTelemetryData{Count: 2},
+	}
+
+	merged := MergeTelemetry(first, nil, second)
+
+	if merged.TelemetryID != "first" {
+		t.Errorf("Expected the identifier of the first part to be used, but got %s", merged.TelemetryID)
+	}
+	if merged.Endpoint.Count != 4 || merged.Endpoint.AgentCount != 3 || merged.Webhook.Count != 3 {
+		t.Errorf("Expected the counts to be summed, but got %d endpoints, %d agents and %d webhooks", merged.Endpoint.Count, merged.Endpoint.AgentCount, merged.Webhook.Count)
+	}
+	if merged.Endpoint.AgentPercent != 75 {
+		t.Errorf("Expected AgentPercent to be recomputed to 75, but got %f", merged.Endpoint.AgentPercent)
+	}
+	if merged.Endpoint.SampleRate != 1 {
+		t.Errorf("Expected SampleRate to be 1, but got %f", merged.Endpoint.SampleRate)
+	}
+	if merged.Endpoint.AverageSnapshotSuccessRate != 0.5 {
+		t.Errorf("Expected AverageSnapshotSuccessRate to be recomputed from the tracked parts, but got %f", merged.Endpoint.AverageSnapshotSuccessRate)
+	}
+	if merged.Endpoint.CloudProviderDistribution["aws"] != 3 || merged.Endpoint.CloudProviderDistribution["azure"] != 1 {
+		t.Errorf("Expected the cloud provider distributions to be summed, but got %v", merged.Endpoint.CloudProviderDistribution)
+	}
+	if len(merged.Endpoint.Endpoints) != 3 {
+		t.Errorf("Expected the endpoint entries to be concatenated, but got %d entries", len(merged.Endpoint.Endpoints))
+	}
+	if merged.Runtime.Version != "1.23.0,1.24.0" {
+		t.Errorf("Expected the distinct runtime versions to be listed, but got %s", merged.Runtime.Version)
+	}
+	if !merged.Settings.PublicAccessEnabled {
+		t.Errorf("Expected PublicAccessEnabled to be true")
+	}
+	if merged.Team.AverageTeamSize != 2.5 || merged.Team.MaxTeamSize != 4 {
+		t.Errorf("Expected an average team size of 2.5 and a max team size of 4, but got %f and %d", merged.Team.AverageTeamSize, merged.Team.MaxTeamSize)
+	}
+	if merged.ResourceControl.PerTypeCoverage != nil {
+		t.Errorf("Expected the resource control coverage to be dropped, but got %v", merged.ResourceControl.PerTypeCoverage)
+	}
+	if merged.Webhook.TriggeredSinceLastRun != webhookTriggersNotTracked {
+		t.Errorf("Expected TriggeredSinceLastRun to be reported as not tracked, but got %d", merged.Webhook.TriggeredSinceLastRun)
+	}
+}
+
+func TestMergeTelemetryProcess(t *testing.T) {
+	first := &TelemetryData{Process: ProcessTelemetryData{HeapAllocBytes: 1024, NumGoroutine: 10, NumGC: 3, Uptime: 3600}}
+	second := &TelemetryData{Process: ProcessTelemetryData{HeapAllocBytes: 2048, NumGoroutine: 20, NumGC: 4, Uptime: 60}}
+
+	merged := MergeTelemetry(first, second)
+
+	if merged.Process.HeapAllocBytes != 3072 || merged.Process.NumGC != 7 || merged.Process.NumGoroutine != 30 {
+		t.Errorf("Expected the process counts to be summed, but got %+v", merged.Process)
+	}
+	if merged.Process.Uptime != 3600 {
+		t.Errorf("Expected the maximum uptime to be kept, but got %d", merged.Process.Uptime)
+	}
+}
+
+func TestMergeTelemetryNonAdditiveValues(t *testing.T) {
+	endpoint := EndpointEnvironmentTelemetryData{Type: EndpointTypeDocker, CloudProvider: CloudProviderOnPrem}
+	first := &TelemetryData{
+		Endpoint:        EndpointTelemetryData{Count: 3, Endpoints: []EndpointEnvironmentTelemetryData{endpoint, endpoint, endpoint}},
+		Settings:        SettingsTelemetryData{SettingsLastModified: 3600},
+		WindowSize:      3,
+		SheddedSections: []string{"endpoint.endpoints"},
+	}
+	second := &TelemetryData{
+		Settings:        SettingsTelemetryData{SettingsLastModified: 60},
+		WindowSize:      2,
+		SheddedSections: []string{"endpoint.endpoints", "registry.configurations"},
+	}
+
+	merged := MergeTelemetry(first, second)
+
+	if len(merged.Endpoint.Endpoints) != merged.Endpoint.Count {
+		t.Errorf("Expected the identical endpoint entries to be kept, but got %d entries for %d endpoints", len(merged.Endpoint.Endpoints), merged.Endpoint.Count)
+	}
+	if merged.Settings.SettingsLastModified != 3600 || merged.WindowSize != 3 {
+		t.Errorf("Expected the maximum settings age and window size to be kept, but got %d and %d", merged.Settings.SettingsLastModified, merged.WindowSize)
+	}
+	if len(merged.SheddedSections) != 2 {
+		t.Errorf("Expected the duplicate shedded sections to be removed, but got %v", merged.SheddedSections)
+	}
+
+	second.Settings.SettingsLastModified = settingsNeverModified
+	merged = MergeTelemetry(first, second)
+
+	if merged.Settings.SettingsLastModified != settingsNeverModified {
+		t.Errorf("Expected settings never modified in a part to be reported as such, but got %d", merged.Settings.SettingsLastModified)
+	}
+}
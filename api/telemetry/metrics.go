@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"time"
+)
+
+const (
+	// TimingRun is the duration of the whole run
+	TimingRun = "run"
+	// TimingCompute is the duration of the computation of the telemetry data
+	TimingCompute = "compute"
+	// TimingSend is the duration of the send, including the batching. It is not recorded when nothing is sent.
+	TimingSend = "send"
+)
+
+// MetricsRecorder receives the result and the timings of every telemetry run, e.g. to expose them
+// to a metrics system. The timings are keyed by TimingRun, TimingCompute and TimingSend.
+type MetricsRecorder interface {
+	RecordRun(result *TelemetrySendResult, timings map[string]time.Duration)
+}
+
+type noopMetricsRecorder struct{}
+
+func (noopMetricsRecorder) RecordRun(result *TelemetrySendResult, timings map[string]time.Duration) {}
+
+// recordRun records the duration of the run started at start and passes the result and the timings
+// to the metrics recorder of the context
+func (runner *TelemetryJobRunner) recordRun(result *TelemetrySendResult, timings map[string]time.Duration, start time.Time) {
+	timings[TimingRun] = time.Since(start)
+	runner.context.MetricsRecorder.RecordRun(result, timings)
+}
@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeMetricsRecorder struct {
+	results []*TelemetrySendResult
+	timings []map[string]time.Duration
+}
+
+func (recorder *fakeMetricsRecorder) RecordRun(result *TelemetrySendResult, timings map[string]time.Duration) {
+	recorder.results = append(recorder.results, result)
+	recorder.timings = append(recorder.timings, timings)
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := &fakeMetricsRecorder{}
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.MetricsRecorder = recorder
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	if len(recorder.results) != 1 {
+		t.Fatalf("Expected the recorder to be called once, but it was called %d times", len(recorder.results))
+	}
+	if recorder.results[0] != result || !result.Sent {
+		t.Errorf("Expected the recorder to receive the result of the run")
+	}
+
+	timings := recorder.timings[0]
+	for _, timing := range []string{TimingRun, TimingCompute, TimingSend} {
+		if timings[timing] <= 0 {
+			t.Errorf("Expected the %s timing to be recorded, but got %s", timing, timings[timing])
+		}
+	}
+	if timings[TimingRun] < timings[TimingCompute]+timings[TimingSend] {
+		t.Errorf("Expected the run timing to include the compute and send timings, but got %v", timings)
+	}
+}
+
+func TestMetricsRecorderWithoutSend(t *testing.T) {
+	recorder := &fakeMetricsRecorder{}
+
+	context := newTestTelemetryJobContext()
+	context.SendDisabled = true
+	context.MetricsRecorder = recorder
+	runner := NewTelemetryJobRunner(nil, context)
+
+	runner.RunWithResult()
+
+	if len(recorder.timings) != 1 {
+		t.Fatalf("Expected the recorder to be called once, but it was called %d times", len(recorder.timings))
+	}
+	if _, ok := recorder.timings[0][TimingSend]; ok {
+		t.Errorf("Expected the send timing not to be recorded when nothing is sent")
+	}
+}
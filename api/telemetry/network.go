@@ -0,0 +1,43 @@
+package telemetry
+
+import (
+	"sort"
+
+	"github.com/portainer/portainer/api"
+)
+
+// snapshotNetwork represents the subset of a Docker network stored inside a snapshot
+// that is used by the telemetry job.
+type snapshotNetwork struct {
+	Driver string `json:"Driver"`
+}
+
+// endpointNetworkDrivers returns the number of networks of each driver reported in the latest
+// snapshot of the endpoint, sorted by driver. It returns an empty slice when no snapshot is available.
+func endpointNetworkDrivers(endpoint *portainer.Endpoint) []NetworkDriverTelemetryData {
+	drivers := make([]NetworkDriverTelemetryData, 0)
+
+	if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].SnapshotRaw.Networks == nil {
+		return drivers
+	}
+
+	var networks []snapshotNetwork
+	err := decodeSnapshotRaw(endpoint.Snapshots[0].SnapshotRaw.Networks, &networks)
+	if err != nil {
+		return drivers
+	}
+
+	counts := make(map[string]int)
+	for _, network := range networks {
+		if network.Driver != "" {
+			counts[network.Driver]++
+		}
+	}
+
+	for driver, count := range counts {
+		drivers = append(drivers, NetworkDriverTelemetryData{Driver: driver, Count: count})
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i].Driver < drivers[j].Driver })
+
+	return drivers
+}
@@ -0,0 +1,38 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestEndpointNetworkDrivers(t *testing.T) {
+	endpoint := portainer.Endpoint{
+		Type: portainer.DockerEnvironment,
+		Snapshots: []portainer.Snapshot{{SnapshotRaw: portainer.SnapshotRaw{Networks: []interface{}{
+			map[string]interface{}{"Name": "bridge", "Driver": "bridge"},
+			map[string]interface{}{"Name": "host", "Driver": "host"},
+			map[string]interface{}{"Name": "ingress", "Driver": "overlay"},
+			map[string]interface{}{"Name": "app", "Driver": "overlay"},
+			map[string]interface{}{"Name": "none", "Driver": "null"},
+		}}}},
+	}
+
+	drivers := endpointNetworkDrivers(&endpoint)
+
+	expected := []NetworkDriverTelemetryData{
+		{Driver: "bridge", Count: 1},
+		{Driver: "host", Count: 1},
+		{Driver: "null", Count: 1},
+		{Driver: "overlay", Count: 2},
+	}
+	if !reflect.DeepEqual(drivers, expected) {
+		t.Errorf("Expected network drivers %v, but got %v", expected, drivers)
+	}
+
+	drivers = endpointNetworkDrivers(&portainer.Endpoint{Type: portainer.DockerEnvironment})
+	if drivers == nil || len(drivers) != 0 {
+		t.Errorf("Expected an empty slice when no snapshot is available, but got %v", drivers)
+	}
+}
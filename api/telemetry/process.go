@@ -0,0 +1,19 @@
+package telemetry
+
+import (
+	"runtime"
+)
+
+// computeProcessTelemetry reads the memory statistics of the Go runtime. The uptime is measured
+// from the creation of the job context, which happens when Portainer starts.
+func computeProcessTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	data.Process.HeapAllocBytes = memStats.HeapAlloc
+	data.Process.NumGoroutine = runtime.NumGoroutine()
+	data.Process.NumGC = memStats.NumGC
+	data.Process.Uptime = int64(context.now().Sub(context.startTime).Seconds())
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeProcessTelemetry(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	context := newTestTelemetryJobContext()
+	context.now = func() time.Time { return now }
+	context.startTime = now.Add(-90 * time.Second)
+
+	data := &TelemetryData{}
+	err := computeProcessTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Process.HeapAllocBytes == 0 {
+		t.Errorf("Expected HeapAllocBytes to be populated")
+	}
+	if data.Process.NumGoroutine <= 0 {
+		t.Errorf("Expected NumGoroutine to be positive, but it was %d instead", data.Process.NumGoroutine)
+	}
+	if data.Process.Uptime != 90 {
+		t.Errorf("Expected Uptime to be 90, but it was %d instead", data.Process.Uptime)
+	}
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const prometheusMetricPrefix = "portainer"
+
+// WritePrometheus writes the numeric fields of the telemetry data as gauges using the
+// Prometheus text exposition format. Metric names are built from the section and field names,
+// e.g. Endpoint.PendingEdgeCount is exposed as portainer_endpoint_pending_edge_count.
+// Boolean fields are exposed as 0/1 gauges, other non-numeric fields are skipped.
+func (d *TelemetryData) WritePrometheus(w io.Writer) error {
+	return walkNumericFields(reflect.ValueOf(d).Elem(), []string{prometheusMetricPrefix}, func(path []string, value reflect.Value) error {
+		name := strings.Join(path, "_")
+
+		// Integers are written as is, as large counts cannot be represented exactly as float64
+		var metric string
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			metric = fmt.Sprintf("%d", value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			metric = fmt.Sprintf("%d", value.Uint())
+		default:
+			metric = fmt.Sprintf("%g", numericFieldValue(value))
+		}
+
+		_, err := fmt.Fprintf(w, "# TYPE %s gauge\n%s %s\n", name, name, metric)
+		return err
+	})
+}
+
+// toSnakeCase converts a Go field name to snake case, keeping acronyms together
+// (e.g. TLSSkipVerifyCount becomes tls_skip_verify_count).
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previousIsLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousIsLower || (unicode.IsUpper(runes[i-1]) && nextIsLower) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	data := &TelemetryData{
+		Endpoint: EndpointTelemetryData{
+			Count:            3,
+			EdgeCount:        2,
+			PendingEdgeCount: 1,
+		},
+		Runtime: RuntimeTelemetryData{
+			Version:  "1.24.0",
+			Platform: "linux",
+		},
+	}
+
+	var buffer bytes.Buffer
+	err := data.WritePrometheus(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := buffer.String()
+
+	expectedMetrics := []string{
+		"# TYPE portainer_endpoint_count gauge\nportainer_endpoint_count 3\n",
+		"# TYPE portainer_endpoint_edge_count gauge\nportainer_endpoint_edge_count 2\n",
+		"# TYPE portainer_endpoint_pending_edge_count gauge\nportainer_endpoint_pending_edge_count 1\n",
+		"# TYPE portainer_endpoint_docker_count gauge\nportainer_endpoint_docker_count 0\n",
+	}
+	for _, metric := range expectedMetrics {
+		if !strings.Contains(output, metric) {
+			t.Errorf("Expected output to contain %q, but it was %q instead", metric, output)
+		}
+	}
+
+	if strings.Contains(output, "portainer_runtime") {
+		t.Errorf("Expected non-numeric runtime fields to be skipped, but output was %q", output)
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"Count":              "count",
+		"PendingEdgeCount":   "pending_edge_count",
+		"TLSSkipVerifyCount": "tls_skip_verify_count",
+	}
+
+	for input, expected := range cases {
+		result := toSnakeCase(input)
+		if result != expected {
+			t.Errorf("Expected %s to be converted to %s, but it was %s instead", input, expected, result)
+		}
+	}
+}
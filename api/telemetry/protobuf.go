@@ -0,0 +1,259 @@
+package telemetry
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+const (
+	protobufWireVarint  = 0
+	protobufWireFixed64 = 1
+	protobufWireBytes   = 2
+	protobufWireFixed32 = 5
+
+	protobufContentType = "application/x-protobuf"
+)
+
+var errInvalidProtobufPayload = errors.New("invalid protobuf payload")
+
+// MarshalProtobuf encodes the telemetry data using the protobuf (proto3) wire format.
+// The message schema is derived from the Go structures: every field is numbered with its
+// protobuf struct tag, e.g. `protobuf:"3"`. A field number must never change or be reused
+// once released, new fields take the next unused number of their structure.
+// Signed integers are encoded as int64, unsigned integers as uint64, floats as double,
+// nested structures as embedded messages, slices as repeated fields and maps as repeated
+// key/value entries (key is field 1, value is field 2). Zero values are omitted, except
+// for the elements of a slice.
+func (d *TelemetryData) MarshalProtobuf() ([]byte, error) {
+	return appendProtobufMessage(nil, reflect.ValueOf(d).Elem())
+}
+
+// UnmarshalProtobuf decodes a payload encoded with MarshalProtobuf into the telemetry data.
+// Unknown fields are ignored.
+func (d *TelemetryData) UnmarshalProtobuf(payload []byte) error {
+	return decodeProtobufMessage(payload, reflect.ValueOf(d).Elem())
+}
+
+// protobufFieldNumber returns the field number declared by the protobuf struct tag of the field.
+func protobufFieldNumber(field reflect.StructField) (int, error) {
+	number, err := strconv.Atoi(field.Tag.Get("protobuf"))
+	if err != nil || number < 1 {
+		return 0, errors.New("missing protobuf field number: " + field.Name)
+	}
+	return number, nil
+}
+
+func appendProtobufMessage(buffer []byte, value reflect.Value) ([]byte, error) {
+	valueType := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		number, err := protobufFieldNumber(valueType.Field(i))
+		if err != nil {
+			return nil, err
+		}
+
+		buffer, err = appendProtobufField(buffer, number, value.Field(i), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buffer, nil
+}
+
+// appendProtobufField encodes the value as the given field. Zero values are skipped unless
+// keepZero is set, which is used for slice elements so that the length and order of a
+// repeated field are preserved.
+func appendProtobufField(buffer []byte, number int, value reflect.Value, keepZero bool) ([]byte, error) {
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			var err error
+			buffer, err = appendProtobufField(buffer, number, value.Index(i), true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buffer, nil
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			entry, err := appendProtobufField(nil, 1, key, false)
+			if err != nil {
+				return nil, err
+			}
+			entry, err = appendProtobufField(entry, 2, value.MapIndex(key), false)
+			if err != nil {
+				return nil, err
+			}
+			buffer = appendProtobufBytes(buffer, number, entry)
+		}
+		return buffer, nil
+	case reflect.Struct:
+		message, err := appendProtobufMessage(nil, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(message) == 0 && !keepZero {
+			return buffer, nil
+		}
+		return appendProtobufBytes(buffer, number, message), nil
+	}
+
+	if value.IsZero() && !keepZero {
+		return buffer, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buffer = appendProtobufTag(buffer, number, protobufWireVarint)
+		return appendVarint(buffer, uint64(value.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buffer = appendProtobufTag(buffer, number, protobufWireVarint)
+		return appendVarint(buffer, value.Uint()), nil
+	case reflect.Bool:
+		buffer = appendProtobufTag(buffer, number, protobufWireVarint)
+		if !value.Bool() {
+			return append(buffer, 0), nil
+		}
+		return append(buffer, 1), nil
+	case reflect.Float64:
+		buffer = appendProtobufTag(buffer, number, protobufWireFixed64)
+		return appendFixed64(buffer, math.Float64bits(value.Float())), nil
+	case reflect.String:
+		return appendProtobufBytes(buffer, number, []byte(value.String())), nil
+	}
+
+	return nil, errors.New("unsupported protobuf field type: " + value.Type().String())
+}
+
+func appendProtobufTag(buffer []byte, number int, wireType int) []byte {
+	return appendVarint(buffer, uint64(number<<3|wireType))
+}
+
+func appendProtobufBytes(buffer []byte, number int, data []byte) []byte {
+	buffer = appendProtobufTag(buffer, number, protobufWireBytes)
+	buffer = appendVarint(buffer, uint64(len(data)))
+	return append(buffer, data...)
+}
+
+func appendVarint(buffer []byte, value uint64) []byte {
+	var data [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(data[:], value)
+	return append(buffer, data[:n]...)
+}
+
+func appendFixed64(buffer []byte, value uint64) []byte {
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], value)
+	return append(buffer, data[:]...)
+}
+
+func decodeProtobufMessage(payload []byte, value reflect.Value) error {
+	fields := make(map[int]int, value.NumField())
+	valueType := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		number, err := protobufFieldNumber(valueType.Field(i))
+		if err != nil {
+			return err
+		}
+		fields[number] = i
+	}
+
+	for len(payload) > 0 {
+		tag, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return errInvalidProtobufPayload
+		}
+		payload = payload[n:]
+
+		number, wireType := int(tag>>3), int(tag&7)
+
+		var raw uint64
+		var data []byte
+		switch wireType {
+		case protobufWireVarint:
+			raw, n = binary.Uvarint(payload)
+			if n <= 0 {
+				return errInvalidProtobufPayload
+			}
+			payload = payload[n:]
+		case protobufWireFixed64:
+			if len(payload) < 8 {
+				return errInvalidProtobufPayload
+			}
+			raw = binary.LittleEndian.Uint64(payload)
+			payload = payload[8:]
+		case protobufWireFixed32:
+			if len(payload) < 4 {
+				return errInvalidProtobufPayload
+			}
+			payload = payload[4:]
+			continue
+		case protobufWireBytes:
+			length, n := binary.Uvarint(payload)
+			if n <= 0 || uint64(len(payload)-n) < length {
+				return errInvalidProtobufPayload
+			}
+			data = payload[n : n+int(length)]
+			payload = payload[n+int(length):]
+		default:
+			return errInvalidProtobufPayload
+		}
+
+		index, ok := fields[number]
+		if !ok {
+			continue
+		}
+
+		err := decodeProtobufField(value.Field(index), raw, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeProtobufField(field reflect.Value, raw uint64, data []byte) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(raw))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(raw)
+	case reflect.Bool:
+		field.SetBool(raw != 0)
+	case reflect.Float64:
+		field.SetFloat(math.Float64frombits(raw))
+	case reflect.String:
+		field.SetString(string(data))
+	case reflect.Struct:
+		return decodeProtobufMessage(data, field)
+	case reflect.Slice:
+		element := reflect.New(field.Type().Elem()).Elem()
+		err := decodeProtobufField(element, raw, data)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.Append(field, element))
+	case reflect.Map:
+		entryType := reflect.StructOf([]reflect.StructField{
+			{Name: "Key", Type: field.Type().Key(), Tag: `protobuf:"1"`},
+			{Name: "Value", Type: field.Type().Elem(), Tag: `protobuf:"2"`},
+		})
+		entry := reflect.New(entryType).Elem()
+		err := decodeProtobufMessage(data, entry)
+		if err != nil {
+			return err
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		field.SetMapIndex(entry.Field(0), entry.Field(1))
+	}
+
+	return nil
+}
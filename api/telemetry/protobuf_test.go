@@ -0,0 +1,194 @@
+package telemetry
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func sampleTelemetryData() *TelemetryData {
+	return &TelemetryData{
+		TelemetryID:      "5b2f0b0e-7c6a-4f4e-9d0e-0e9c1e4b7d0a",
+		IdentifierSource: IdentifierSourceGenerated,
+		Endpoint: EndpointTelemetryData{
+			Count:                     3,
+			DockerCount:               2,
+			EdgeCount:                 1,
+			CloudProviderDistribution: map[string]int{CloudProviderAWS: 2, CloudProviderOnPrem: 1},
+			Endpoints: []EndpointEnvironmentTelemetryData{
+				{Type: EndpointTypeDocker, CloudProvider: CloudProviderAWS},
+				{Type: EndpointTypeEdge, CloudProvider: CloudProviderOnPrem},
+			},
+		},
+		Registry: RegistryTelemetryData{
+			Count: 1,
+			Configurations: []RegistryConfigurationTelemetryData{
+				{Type: RegistryConfigurationTypeGitlab, Authentication: true, Options: map[string]bool{RegistryOptionGitlabProject: true}},
+			},
+		},
+		Runtime:  RuntimeTelemetryData{Version: "1.24.0", Platform: "linux", Arch: "amd64"},
+		Settings: SettingsTelemetryData{PublicAccessEnabled: true},
+		Team:     TeamTelemetryData{Count: 2, AverageTeamSize: 2.5, MaxTeamSize: 4},
+		EdgeCompute: EdgeComputeTelemetryData{
+			ConnectedEdgeAgents: -1,
+		},
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	data := sampleTelemetryData()
+
+	payload, err := data.MarshalProtobuf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &TelemetryData{}
+	err = decoded.UnmarshalProtobuf(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data, decoded) {
+		t.Errorf("Expected the decoded data to match the encoded data\nexpected: %+v\ndecoded:  %+v", data, decoded)
+	}
+}
+
+func TestProtobufRoundTripProcess(t *testing.T) {
+	data := sampleTelemetryData()
+	data.Process = ProcessTelemetryData{HeapAllocBytes: math.MaxUint64, NumGoroutine: 12, NumGC: math.MaxUint32, Uptime: 3600}
+
+	payload, err := data.MarshalProtobuf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &TelemetryData{}
+	err = decoded.UnmarshalProtobuf(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data.Process, decoded.Process) {
+		t.Errorf("Expected the process section to round-trip, but got %+v instead of %+v", decoded.Process, data.Process)
+	}
+}
+
+func TestSendTelemetryProtobuf(t *testing.T) {
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.Format = PayloadFormatProtobuf
+
+	err := context.sendTelemetry(sampleTelemetryData(), &TelemetrySendResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != protobufContentType {
+		t.Errorf("Expected Content-Type to be %s, but it was %s instead", protobufContentType, contentType)
+	}
+
+	decoded := &TelemetryData{}
+	err = decoded.UnmarshalProtobuf(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Endpoint.Count != 3 {
+		t.Errorf("Expected the decoded endpoint count to be 3, but it was %d instead", decoded.Endpoint.Count)
+	}
+}
+
+func TestProtobufFieldNumbers(t *testing.T) {
+	checkProtobufFieldNumbers(t, reflect.TypeOf(TelemetryData{}), map[reflect.Type]bool{})
+}
+
+func checkProtobufFieldNumbers(t *testing.T, structType reflect.Type, visited map[reflect.Type]bool) {
+	if visited[structType] {
+		return
+	}
+	visited[structType] = true
+
+	numbers := make(map[int]string)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		number, err := protobufFieldNumber(field)
+		if err != nil {
+			t.Errorf("Expected %s.%s to declare a protobuf field number", structType.Name(), field.Name)
+			continue
+		}
+		if other, ok := numbers[number]; ok {
+			t.Errorf("Expected %s.%s to have a unique protobuf field number, but %d is also used by %s", structType.Name(), field.Name, number, other)
+		}
+		numbers[number] = field.Name
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			checkProtobufFieldNumbers(t, fieldType, visited)
+		}
+	}
+}
+
+func TestProtobufFieldNumbersAreStable(t *testing.T) {
+	data := &TelemetryData{Endpoint: EndpointTelemetryData{SnapshottedInLastInterval: 1, CloudProviderDistribution: map[string]int{CloudProviderAWS: 1}}}
+
+	payload, err := data.MarshalProtobuf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Endpoint is field 4, SnapshottedInLastInterval its field 11 and CloudProviderDistribution its field 13.
+	expected := []byte{0x22, 0x0b, 0x58, 0x01, 0x6a, 0x07, 0x0a, 0x03, 'a', 'w', 's', 0x10, 0x01}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Errorf("Expected the payload to be %x, but it was %x instead", expected, payload)
+	}
+}
+
+func TestProtobufMissingFieldNumber(t *testing.T) {
+	value := struct {
+		Count int
+	}{Count: 1}
+
+	_, err := appendProtobufMessage(nil, reflect.ValueOf(value))
+	if err == nil {
+		t.Error("Expected an error for a field without a protobuf field number")
+	}
+}
+
+func TestProtobufRoundTripZeroSliceElements(t *testing.T) {
+	data := sampleTelemetryData()
+	data.Endpoint.Endpoints = []EndpointEnvironmentTelemetryData{{}, {Type: EndpointTypeDocker}, {}}
+	data.SheddedSections = []string{"", "Registry", ""}
+
+	payload, err := data.MarshalProtobuf()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &TelemetryData{}
+	err = decoded.UnmarshalProtobuf(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data.Endpoint.Endpoints, decoded.Endpoint.Endpoints) {
+		t.Errorf("Expected the endpoints to round-trip, but got %+v instead of %+v", decoded.Endpoint.Endpoints, data.Endpoint.Endpoints)
+	}
+	if !reflect.DeepEqual(data.SheddedSections, decoded.SheddedSections) {
+		t.Errorf("Expected the shedded sections to round-trip, but got %q instead of %q", decoded.SheddedSections, data.SheddedSections)
+	}
+}
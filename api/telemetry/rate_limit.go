@@ -0,0 +1,19 @@
+package telemetry
+
+import (
+	"time"
+)
+
+// waitRateLimit blocks until the rate limiter allows the next item to be processed
+func (context *TelemetryJobContext) waitRateLimit() {
+	if context.RateLimiter == nil {
+		return
+	}
+
+	reservation := context.RateLimiter.Reserve()
+	if !reservation.OK() {
+		return
+	}
+
+	time.Sleep(reservation.Delay())
+}
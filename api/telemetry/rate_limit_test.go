@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+	"golang.org/x/time/rate"
+)
+
+func TestComputeEndpointTelemetryRateLimit(t *testing.T) {
+	endpoints := make([]portainer.Endpoint, 6)
+	for i := range endpoints {
+		endpoints[i] = portainer.Endpoint{ID: portainer.EndpointID(i + 1), Type: portainer.DockerEnvironment}
+	}
+
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: endpoints}
+	context.RateLimiter = rate.NewLimiter(rate.Every(20*time.Millisecond), 1)
+
+	start := time.Now()
+	err := computeEndpointTelemetry(context, &TelemetryData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first endpoint uses the burst, the 5 others wait 20ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected the computation to take at least 90ms, but it took %s", elapsed)
+	}
+}
@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// RegistryConfigurationTypeQuay represents a Quay.io registry
+	RegistryConfigurationTypeQuay = "quay"
+	// RegistryConfigurationTypeAzure represents an Azure container registry
+	RegistryConfigurationTypeAzure = "azure"
+	// RegistryConfigurationTypeGitlab represents a Gitlab registry
+	RegistryConfigurationTypeGitlab = "gitlab"
+	// RegistryConfigurationTypeCustom represents a custom registry
+	RegistryConfigurationTypeCustom = "custom"
+
+	// RegistryOptionGitlabProject is set when a Gitlab registry is scoped to a project
+	RegistryOptionGitlabProject = "hasGitlabProject"
+	// RegistryOptionGitlabInstance is set when a Gitlab registry references a Gitlab instance
+	RegistryOptionGitlabInstance = "hasGitlabInstance"
+	// RegistryOptionManagementConfiguration is set when the registry is configured for the registry management extension
+	RegistryOptionManagementConfiguration = "hasManagementConfiguration"
+	// RegistryOptionManagementTLS is set when the registry management configuration uses TLS
+	RegistryOptionManagementTLS = "hasManagementTLS"
+
+	// StackRegistryUnknown is used for the stacks that do not reference any configured registry
+	StackRegistryUnknown = "unknown"
+
+	registryURLHashLength = 16
+
+	// unusedRegistriesUnknown is reported as UnusedCount when the file of a stack cannot be read,
+	// as any registry could be referenced by that stack
+	unusedRegistriesUnknown = -1
+)
+
+func computeRegistryTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	registries, err := context.registries()
+	if err != nil {
+		return err
+	}
+
+	data.Registry.Count = len(registries)
+	data.Registry.Configurations = make([]RegistryConfigurationTelemetryData, 0)
+
+	for _, registry := range registries {
+		configuration := RegistryConfigurationTelemetryData{
+			Authentication: registry.Authentication,
+			Options:        registryOptions(&registry),
+		}
+
+		configuration.Type = RegistryTypeTelemetry(registry.Type)
+		if context.HashSensitive {
+			configuration.URLHash = hashRegistryURL(registry.URL)
+		}
+
+		data.Registry.Configurations = append(data.Registry.Configurations, configuration)
+	}
+
+	if context.ProbeRegistries {
+		computeRegistryReachabilityTelemetry(context, registries, data)
+	}
+
+	if context.ComputeUnusedRegistries {
+		err := computeUnusedRegistries(context, registries, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if context.ComputeStacksPerRegistry {
+		return computeStacksPerRegistry(context, registries, data)
+	}
+
+	return nil
+}
+
+// hashRegistryURL returns a prefix of the SHA-256 hash of a registry URL. URLs differing only
+// by their case, surrounding spaces or trailing slash share the same hash.
+func hashRegistryURL(registryURL string) string {
+	normalized := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(registryURL)), "/")
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])[:registryURLHashLength]
+}
+
+// RegistryTypeTelemetry returns the telemetry representation of a registry type.
+// Unknown registry types are reported as custom registries.
+func RegistryTypeTelemetry(registryType portainer.RegistryType) string {
+	switch registryType {
+	case portainer.QuayRegistry:
+		return RegistryConfigurationTypeQuay
+	case portainer.AzureRegistry:
+		return RegistryConfigurationTypeAzure
+	case portainer.GitlabRegistry:
+		return RegistryConfigurationTypeGitlab
+	default:
+		return RegistryConfigurationTypeCustom
+	}
+}
+
+// registryOptions returns the provider specific options configured on a registry.
+// Only the presence of an option is reported, never its value.
+func registryOptions(registry *portainer.Registry) map[string]bool {
+	options := make(map[string]bool)
+
+	if registry.Type == portainer.GitlabRegistry {
+		if registry.Gitlab.ProjectID != 0 || registry.Gitlab.ProjectPath != "" {
+			options[RegistryOptionGitlabProject] = true
+		}
+		if registry.Gitlab.InstanceURL != "" {
+			options[RegistryOptionGitlabInstance] = true
+		}
+	}
+
+	if registry.ManagementConfiguration != nil {
+		options[RegistryOptionManagementConfiguration] = true
+		if registry.ManagementConfiguration.TLSConfig.TLS {
+			options[RegistryOptionManagementTLS] = true
+		}
+	}
+
+	return options
+}
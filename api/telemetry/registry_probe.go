@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+// defaultRegistryProbeTimeout is the default timeout of the request used to probe a registry
+const defaultRegistryProbeTimeout = 5 * time.Second
+
+// computeRegistryReachabilityTelemetry probes the Docker registry API base endpoint (/v2/) of
+// each registry. Any HTTP response, including an authentication challenge, means the registry
+// is reachable. The requests are sent without credentials.
+func computeRegistryReachabilityTelemetry(context *TelemetryJobContext, registries []portainer.Registry, data *TelemetryData) {
+	client := &http.Client{
+		Timeout: context.registryProbeTimeout,
+	}
+
+	for _, registry := range registries {
+		if probeRegistry(client, &registry) {
+			data.Registry.ReachableCount++
+		} else {
+			data.Registry.UnreachableCount++
+		}
+	}
+}
+
+func probeRegistry(client *http.Client, registry *portainer.Registry) bool {
+	registryURL := strings.TrimSuffix(registry.URL, "/")
+	if !strings.HasPrefix(registryURL, "http://") && !strings.HasPrefix(registryURL, "https://") {
+		registryURL = "https://" + registryURL
+	}
+
+	response, err := client.Get(registryURL + "/v2/")
+	if err != nil {
+		return false
+	}
+	response.Body.Close()
+
+	return true
+}
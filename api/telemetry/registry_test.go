@@ -0,0 +1,226 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestRegistryOptions(t *testing.T) {
+	cases := []struct {
+		name     string
+		registry portainer.Registry
+		expected []string
+	}{
+		{
+			name:     "Azure registry without options",
+			registry: portainer.Registry{Type: portainer.AzureRegistry, URL: "myregistry.azurecr.io"},
+			expected: []string{},
+		},
+		{
+			name: "Azure registry with management configuration",
+			registry: portainer.Registry{
+				Type:                    portainer.AzureRegistry,
+				ManagementConfiguration: &portainer.RegistryManagementConfiguration{TLSConfig: portainer.TLSConfiguration{TLS: true}},
+			},
+			expected: []string{RegistryOptionManagementConfiguration, RegistryOptionManagementTLS},
+		},
+		{
+			name:     "Gitlab registry without project",
+			registry: portainer.Registry{Type: portainer.GitlabRegistry, URL: "registry.gitlab.com"},
+			expected: []string{},
+		},
+		{
+			name: "Gitlab registry with project",
+			registry: portainer.Registry{
+				Type:   portainer.GitlabRegistry,
+				URL:    "registry.gitlab.com",
+				Gitlab: portainer.GitlabRegistryData{ProjectID: 42, ProjectPath: "group/project", InstanceURL: "https://gitlab.com"},
+			},
+			expected: []string{RegistryOptionGitlabProject, RegistryOptionGitlabInstance},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			options := registryOptions(&c.registry)
+
+			if len(options) != len(c.expected) {
+				t.Errorf("Expected %d options, but got %v", len(c.expected), options)
+			}
+			for _, option := range c.expected {
+				if !options[option] {
+					t.Errorf("Expected option %s to be set, but got %v", option, options)
+				}
+			}
+
+			payload, _ := json.Marshal(options)
+			if strings.Contains(string(payload), "group/project") || strings.Contains(string(payload), "gitlab.com") {
+				t.Errorf("Expected options to not contain any value, but got %s", payload)
+			}
+		})
+	}
+}
+
+func TestComputeStacksPerRegistry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.ComputeStacksPerRegistry = true
+	context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+		{Type: portainer.CustomRegistry, URL: "registry.example.com:5000"},
+	}}
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/2", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/3", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3'\nservices:\n  web:\n    image: registry.example.com:5000/app/web:1.0\n",
+		"/data/compose/2/docker-compose.yml": "version: '3'\nservices:\n  db:\n    image: \"postgres:12\"\n",
+	}}
+
+	data := &TelemetryData{}
+	err := computeRegistryTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Registry.StacksPerRegistry[RegistryConfigurationTypeCustom] != 1 {
+		t.Errorf("Expected 1 stack using a custom registry, but got %d", data.Registry.StacksPerRegistry[RegistryConfigurationTypeCustom])
+	}
+	if data.Registry.StacksPerRegistry[StackRegistryUnknown] != 2 {
+		t.Errorf("Expected 2 stacks with an unknown registry, but got %d", data.Registry.StacksPerRegistry[StackRegistryUnknown])
+	}
+}
+
+func TestRegistryTypeTelemetry(t *testing.T) {
+	cases := []struct {
+		registryType portainer.RegistryType
+		expected     string
+	}{
+		{portainer.AzureRegistry, RegistryConfigurationTypeAzure},
+		{portainer.QuayRegistry, RegistryConfigurationTypeQuay},
+		{portainer.GitlabRegistry, RegistryConfigurationTypeGitlab},
+		{portainer.CustomRegistry, RegistryConfigurationTypeCustom},
+		{portainer.RegistryType(42), RegistryConfigurationTypeCustom},
+	}
+
+	for _, c := range cases {
+		registryType := RegistryTypeTelemetry(c.registryType)
+		if registryType != c.expected {
+			t.Errorf("Expected registry type %d to be reported as %s, but it was %s instead", c.registryType, c.expected, registryType)
+		}
+	}
+}
+
+func TestComputeRegistryReachabilityTelemetry(t *testing.T) {
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected the probe to be sent without credentials")
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer reachable.Close()
+
+	release := make(chan struct{})
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer unreachable.Close()
+	defer close(release)
+
+	context := newTestTelemetryJobContext()
+	context.ProbeRegistries = true
+	context.registryProbeTimeout = 50 * time.Millisecond
+	context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+		{ID: 1, URL: reachable.URL, Authentication: true, Username: "user", Password: "password"},
+		{ID: 2, URL: unreachable.URL},
+	}}
+
+	data := &TelemetryData{}
+	err := computeRegistryTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Registry.ReachableCount != 1 || data.Registry.UnreachableCount != 1 {
+		t.Errorf("Expected 1 reachable and 1 unreachable registry, but got %d and %d", data.Registry.ReachableCount, data.Registry.UnreachableCount)
+	}
+}
+
+func TestComputeRegistryTelemetryHashSensitive(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.HashSensitive = true
+	context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+		{ID: 1, Type: portainer.CustomRegistry, URL: "registry.internal.corp:5000"},
+		{ID: 2, Type: portainer.CustomRegistry, URL: "Registry.Internal.Corp:5000/"},
+		{ID: 3, Type: portainer.CustomRegistry, URL: "mirror.internal.corp"},
+	}}
+
+	data := &TelemetryData{}
+	err := computeRegistryTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ToLower(string(payload)), "internal.corp") {
+		t.Errorf("Expected no registry URL in the payload, but got %s", payload)
+	}
+
+	configurations := data.Registry.Configurations
+	if configurations[0].URLHash == "" || configurations[0].URLHash != configurations[1].URLHash {
+		t.Errorf("Expected identical URLs to share the same hash, but got %q and %q", configurations[0].URLHash, configurations[1].URLHash)
+	}
+	if configurations[0].URLHash == configurations[2].URLHash {
+		t.Errorf("Expected distinct URLs to have distinct hashes")
+	}
+}
+
+func TestComputeUnusedRegistries(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.ComputeUnusedRegistries = true
+	context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+		{Type: portainer.CustomRegistry, URL: "registry.example.com:5000"},
+		{Type: portainer.CustomRegistry, URL: "https://unused.example.com/"},
+		{Type: portainer.GitlabRegistry},
+	}}
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3'\nservices:\n  web:\n    image: registry.example.com:5000/app/web:1.0\n",
+	}}
+
+	data := &TelemetryData{}
+	err := computeRegistryTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Registry.UnusedCount != 1 {
+		t.Errorf("Expected 1 unused registry, but got %d", data.Registry.UnusedCount)
+	}
+
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/2", EntryPoint: "docker-compose.yml"},
+	}}
+
+	data = &TelemetryData{}
+	err = computeRegistryTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Registry.UnusedCount != unusedRegistriesUnknown {
+		t.Errorf("Expected UnusedCount to be %d when a stack file cannot be read, but got %d", unusedRegistriesUnknown, data.Registry.UnusedCount)
+	}
+}
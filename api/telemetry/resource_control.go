@@ -0,0 +1,112 @@
+package telemetry
+
+import (
+	"math"
+
+	"github.com/portainer/portainer/api"
+)
+
+func computeResourceControlTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	resourceControls, err := context.resourceControls()
+	if err != nil {
+		return err
+	}
+
+	data.ResourceControl.Count = len(resourceControls)
+
+	if context.ComputeResourceControlCoverage {
+		err := computeResourceControlCoverage(context, resourceControls, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !context.ComputeDanglingUserGrants {
+		return nil
+	}
+
+	users, err := context.users()
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[portainer.UserID]bool, len(users))
+	for _, user := range users {
+		userIDs[user.ID] = true
+	}
+
+	for _, resourceControl := range resourceControls {
+		context.waitRateLimit()
+
+		for _, access := range resourceControl.UserAccesses {
+			if !userIDs[access.UserID] {
+				data.ResourceControl.DanglingUserGrants++
+			}
+		}
+	}
+
+	return nil
+}
+
+const (
+	// ResourceControlTypeContainer represents the resource controls of the containers
+	ResourceControlTypeContainer = "container"
+	// ResourceControlTypeService represents the resource controls of the services
+	ResourceControlTypeService = "service"
+	// ResourceControlTypeVolume represents the resource controls of the volumes
+	ResourceControlTypeVolume = "volume"
+	// ResourceControlTypeStack represents the resource controls of the stacks
+	ResourceControlTypeStack = "stack"
+)
+
+// coverageResourceControlTypes maps the resource control types that can be compared to the
+// resource counts of the snapshots to their telemetry representation
+var coverageResourceControlTypes = map[portainer.ResourceControlType]string{
+	portainer.ContainerResourceControl: ResourceControlTypeContainer,
+	portainer.ServiceResourceControl:   ResourceControlTypeService,
+	portainer.VolumeResourceControl:    ResourceControlTypeVolume,
+	portainer.StackResourceControl:     ResourceControlTypeStack,
+}
+
+// computeResourceControlCoverage computes, for each type of resource counted in the endpoint snapshots,
+// the number of resource controls of that type over the number of resources. The types without any
+// resource are not reported. The coverage is capped to 1 as resource controls are not removed when
+// a resource is deleted outside of Portainer.
+func computeResourceControlCoverage(context *TelemetryJobContext, resourceControls []portainer.ResourceControl, data *TelemetryData) error {
+	endpoints, err := context.endpoints()
+	if err != nil {
+		return err
+	}
+
+	resources := make(map[string]int)
+	for _, endpoint := range endpoints {
+		if len(endpoint.Snapshots) == 0 {
+			continue
+		}
+
+		snapshot := endpoint.Snapshots[0]
+		sanitizeSnapshot(&snapshot)
+
+		resources[ResourceControlTypeContainer] += snapshot.RunningContainerCount + snapshot.StoppedContainerCount
+		resources[ResourceControlTypeService] += snapshot.ServiceCount
+		resources[ResourceControlTypeVolume] += snapshot.VolumeCount
+		resources[ResourceControlTypeStack] += snapshot.StackCount
+	}
+
+	controls := make(map[string]int)
+	for _, resourceControl := range resourceControls {
+		if resourceType, ok := coverageResourceControlTypes[resourceControl.Type]; ok {
+			controls[resourceType]++
+		}
+	}
+
+	data.ResourceControl.PerTypeCoverage = make(map[string]float64)
+	for resourceType, count := range resources {
+		if count == 0 {
+			continue
+		}
+		data.ResourceControl.PerTypeCoverage[resourceType] = math.Min(float64(controls[resourceType])/float64(count), 1)
+	}
+
+	return nil
+}
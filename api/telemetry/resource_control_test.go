@@ -0,0 +1,72 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeResourceControlTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.userService = &fakeUserService{users: []portainer.User{{ID: 1}, {ID: 2}}}
+	context.resourceControlService = &fakeResourceControlService{resourceControls: []portainer.ResourceControl{
+		{ID: 1, UserAccesses: []portainer.UserResourceAccess{{UserID: 1}, {UserID: 3}}},
+		{ID: 2, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+	}}
+
+	data := &TelemetryData{}
+	err := computeResourceControlTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.ResourceControl.Count != 2 {
+		t.Errorf("Expected 2 resource controls, but got %d", data.ResourceControl.Count)
+	}
+	if data.ResourceControl.DanglingUserGrants != 0 {
+		t.Errorf("Expected dangling grants not to be computed by default, but got %d", data.ResourceControl.DanglingUserGrants)
+	}
+
+	context.ComputeDanglingUserGrants = true
+	data = &TelemetryData{}
+	err = computeResourceControlTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.ResourceControl.DanglingUserGrants != 1 {
+		t.Errorf("Expected 1 dangling user grant, but got %d", data.ResourceControl.DanglingUserGrants)
+	}
+}
+
+func TestComputeResourceControlCoverage(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.ComputeResourceControlCoverage = true
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{RunningContainerCount: 3, StoppedContainerCount: 1, VolumeCount: 1}}},
+		{ID: 2, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{RunningContainerCount: 4}}},
+		{ID: 3, Type: portainer.DockerEnvironment},
+	}}
+	context.resourceControlService = &fakeResourceControlService{resourceControls: []portainer.ResourceControl{
+		{ID: 1, Type: portainer.ContainerResourceControl},
+		{ID: 2, Type: portainer.ContainerResourceControl},
+		{ID: 3, Type: portainer.VolumeResourceControl},
+		{ID: 4, Type: portainer.VolumeResourceControl},
+		{ID: 5, Type: portainer.NetworkResourceControl},
+	}}
+
+	data := &TelemetryData{}
+	err := computeResourceControlTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{
+		ResourceControlTypeContainer: 0.25,
+		ResourceControlTypeVolume:    1,
+	}
+	if !reflect.DeepEqual(data.ResourceControl.PerTypeCoverage, expected) {
+		t.Errorf("Expected the resource control coverage to be %v, but got %v", expected, data.ResourceControl.PerTypeCoverage)
+	}
+}
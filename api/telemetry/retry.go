@@ -0,0 +1,43 @@
+package telemetry
+
+// retryBudget bounds the number of retries of a run. It is shared by the computation
+// of the sections and the send, once exhausted the failures are returned immediately.
+type retryBudget struct {
+	remaining int
+}
+
+func newRetryBudget(size int) *retryBudget {
+	return &retryBudget{remaining: size}
+}
+
+// take consumes a retry from the budget and returns false when the budget is exhausted
+func (budget *retryBudget) take() bool {
+	if budget.remaining <= 0 {
+		return false
+	}
+	budget.remaining--
+	return true
+}
+
+// computeSectionWithRetries computes a section and retries it as long as it fails and the budget allows it.
+// The section is emptied before each retry so that a partial computation is not counted twice.
+// A section skipped because of the store read timeout is not retried, the store is likely still busy.
+func (context *TelemetryJobContext) computeSectionWithRetries(budget *retryBudget, data *TelemetryData, section string, compute func() error) *SectionError {
+	sectionError := context.computeSection(section, compute)
+	for sectionError != nil && sectionError.Err != errStoreReadTimeout && budget.take() {
+		context.logf("[WARN] [telemetry] [message: retrying telemetry section] [section: %s] [err: %s] [retry_budget: %d]\n", section, sectionError.Err, budget.remaining)
+		resetSection(data, section)
+		sectionError = context.computeSection(section, compute)
+	}
+	return sectionError
+}
+
+// sendTelemetryWithRetries sends the data and retries the send as long as it fails and the budget allows it
+func (context *TelemetryJobContext) sendTelemetryWithRetries(budget *retryBudget, data interface{}, result *TelemetrySendResult) error {
+	err := context.sendTelemetry(data, result)
+	for err != nil && budget.take() {
+		context.logf("[WARN] [telemetry] [message: retrying telemetry send] [err: %s] [retry_budget: %d]\n", err, budget.remaining)
+		err = context.sendTelemetry(data, result)
+	}
+	return err
+}
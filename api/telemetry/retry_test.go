@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+type flakyRegistryService struct {
+	portainer.RegistryService
+	failures int
+	calls    int
+}
+
+func (service *flakyRegistryService) Registries() ([]portainer.Registry, error) {
+	service.calls++
+	if service.calls <= service.failures {
+		return nil, errors.New("unable to read registries")
+	}
+	return []portainer.Registry{{ID: 1}}, nil
+}
+
+func TestRetryBudgetRetriesSection(t *testing.T) {
+	registryService := &flakyRegistryService{failures: 1}
+
+	context := newTestTelemetryJobContext()
+	context.SendDisabled = true
+	context.RetryBudget = 3
+	context.registryService = registryService
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	if len(result.SectionErrors) != 0 {
+		t.Errorf("Expected the failing section to be retried, but got %v", result.SectionErrors)
+	}
+	if result.RetryBudgetRemaining != 2 {
+		t.Errorf("Expected 2 retries to remain, but got %d", result.RetryBudgetRemaining)
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.RetryBudget = 2
+	context.registryService = &flakyRegistryService{failures: 1}
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	// The section retry leaves a single retry for the send
+	if count := atomic.LoadInt32(&requests); count != 2 {
+		t.Errorf("Expected the send to be attempted twice, but it was attempted %d times", count)
+	}
+	if result.Sent || result.Err != errInvalidResponseStatus {
+		t.Errorf("Expected the send error to be returned once the budget is exhausted, but got %v", result.Err)
+	}
+	if result.RetryBudgetRemaining != 0 {
+		t.Errorf("Expected the retry budget to be exhausted, but %d retries remain", result.RetryBudgetRemaining)
+	}
+
+	registryService := &flakyRegistryService{failures: 10}
+	context.registryService = registryService
+	requests = 0
+
+	result = runner.RunWithResult()
+
+	if len(result.SectionErrors) == 0 {
+		t.Errorf("Expected the section error to be returned once the budget is exhausted")
+	}
+	if count := atomic.LoadInt32(&requests); count != 1 {
+		t.Errorf("Expected the send not to be retried once the budget is exhausted, but it was attempted %d times", count)
+	}
+}
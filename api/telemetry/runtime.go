@@ -0,0 +1,13 @@
+package telemetry
+
+import (
+	"github.com/portainer/portainer/api"
+)
+
+func computeRuntimeTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	data.Runtime.Version = portainer.APIVersion
+	data.Runtime.Platform = context.platform
+	data.Runtime.Arch = context.arch
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestComputeRuntimeTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.platform = "windows"
+	context.arch = "arm64"
+
+	data := &TelemetryData{}
+	err := computeRuntimeTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Runtime.Platform != "windows" {
+		t.Errorf("Expected Platform to be 'windows', but it was %s instead", data.Runtime.Platform)
+	}
+	if data.Runtime.Arch != "arm64" {
+		t.Errorf("Expected Arch to be 'arm64', but it was %s instead", data.Runtime.Arch)
+	}
+}
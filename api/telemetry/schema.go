@@ -0,0 +1,95 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CurrentSchemaVersion is the version of the telemetry data schema. Fields added to the
+// telemetry data after the first version of the schema must be annotated with the version
+// they were introduced in using the telemetry struct tag, e.g. `telemetry:"since=2"`.
+const CurrentSchemaVersion = 2
+
+// marshalSchemaVersion encodes the data as JSON, omitting the fields introduced after the
+// given schema version. Every field is encoded when version is 0.
+func marshalSchemaVersion(data interface{}, version int) ([]byte, error) {
+	if version <= 0 {
+		return json.Marshal(data)
+	}
+
+	return json.Marshal(schemaValue(reflect.ValueOf(data), version))
+}
+
+func schemaValue(value reflect.Value, version int) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return schemaValue(value.Elem(), version)
+	case reflect.Struct:
+		fields := make(map[string]interface{})
+		valueType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := valueType.Field(i)
+			if field.PkgPath != "" || fieldSinceVersion(field) > version {
+				continue
+			}
+
+			name, omitEmpty := jsonFieldName(field)
+			if name == "-" || (omitEmpty && value.Field(i).IsZero()) {
+				continue
+			}
+
+			fields[name] = schemaValue(value.Field(i), version)
+		}
+		return fields
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() != reflect.Struct {
+			return value.Interface()
+		}
+		elements := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elements[i] = schemaValue(value.Index(i), version)
+		}
+		return elements
+	}
+
+	return value.Interface()
+}
+
+// fieldSinceVersion returns the schema version a field was introduced in, 1 when not annotated
+func fieldSinceVersion(field reflect.StructField) int {
+	for _, option := range strings.Split(field.Tag.Get("telemetry"), ",") {
+		if strings.HasPrefix(option, "since=") {
+			version, err := strconv.Atoi(strings.TrimPrefix(option, "since="))
+			if err == nil {
+				return version
+			}
+		}
+	}
+	return 1
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	options := strings.Split(field.Tag.Get("json"), ",")
+
+	name := options[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	omitEmpty := false
+	for _, option := range options[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type schemaTestData struct {
+	Count   int                 `json:"Count"`
+	Section schemaTestSection   `json:"Section"`
+	Entries []schemaTestSection `json:"Entries"`
+	Label   string              `json:"Label" telemetry:"since=2"`
+}
+
+type schemaTestSection struct {
+	Total   int `json:"Total"`
+	Details int `json:"Details" telemetry:"since=2"`
+}
+
+func TestMarshalSchemaVersion(t *testing.T) {
+	data := &schemaTestData{
+		Count:   1,
+		Section: schemaTestSection{Total: 2, Details: 3},
+		Entries: []schemaTestSection{{Total: 4, Details: 5}},
+		Label:   "label",
+	}
+
+	payload, err := marshalSchemaVersion(data, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"Count":1,"Entries":[{"Total":4}],"Section":{"Total":2}}`
+	if string(payload) != expected {
+		t.Errorf("Expected the v2 fields to be omitted\nexpected: %s\ngot:      %s", expected, payload)
+	}
+
+	payload, err = marshalSchemaVersion(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, _ := json.Marshal(data)
+	if string(payload) != string(full) {
+		t.Errorf("Expected every field to be sent when no schema version is targeted, but got %s", payload)
+	}
+}
@@ -0,0 +1,35 @@
+package telemetry
+
+// telemetrySection represents a section of the telemetry data. A new section is computed
+// by the job once it is appended to telemetrySections.
+type telemetrySection struct {
+	name    string
+	compute func(context *TelemetryJobContext, data *TelemetryData) error
+	// skip, when set, returns true when the section must not be computed
+	skip func(context *TelemetryJobContext, data *TelemetryData) bool
+}
+
+// telemetrySections lists the sections of the telemetry data in the order they are computed.
+// The settings section is computed first as it defines which sections are suppressed.
+var telemetrySections = []telemetrySection{
+	{name: SectionSettings, compute: computeSettingsTelemetry},
+	{name: SectionEndpoint, compute: computeEndpointTelemetry, skip: resourceDetailsExcluded},
+	{name: SectionEdgeCompute, compute: computeEdgeComputeTelemetry},
+	{name: SectionRegistry, compute: computeRegistryTelemetry},
+	{name: SectionResourceControl, compute: computeResourceControlTelemetry},
+	{name: SectionStack, compute: computeStackTelemetry},
+	{name: SectionTeam, compute: computeTeamTelemetry},
+	{name: SectionUser, compute: computeUserTelemetry},
+	{name: SectionWebhook, compute: computeWebhookTelemetry},
+	{name: SectionSecurity, compute: computeSecurityTelemetry},
+	{name: SectionRuntime, compute: computeRuntimeTelemetry},
+	{name: SectionProcess, compute: computeProcessTelemetry, skip: processStatsExcluded},
+}
+
+func resourceDetailsExcluded(context *TelemetryJobContext, data *TelemetryData) bool {
+	return data.Settings.ResourceDetailsExcluded
+}
+
+func processStatsExcluded(context *TelemetryJobContext, data *TelemetryData) bool {
+	return !context.IncludeProcessStats
+}
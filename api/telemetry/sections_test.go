@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestComputeTelemetryRunsAllSections(t *testing.T) {
+	registeredSections := telemetrySections
+	defer func() { telemetrySections = registeredSections }()
+
+	computed := make(map[string]bool)
+	telemetrySections = make([]telemetrySection, 0)
+	for _, section := range registeredSections {
+		section := section
+		compute := section.compute
+		section.compute = func(context *TelemetryJobContext, data *TelemetryData) error {
+			computed[section.name] = true
+			return compute(context, data)
+		}
+		telemetrySections = append(telemetrySections, section)
+	}
+
+	context := newTestTelemetryJobContext()
+	context.IncludeProcessStats = true
+
+	_, sectionErrors, err := computeTelemetry(context, newRetryBudget(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sectionErrors) > 0 {
+		t.Fatalf("Expected no section error, but got %v", sectionErrors)
+	}
+
+	for _, section := range registeredSections {
+		if !computed[section.name] {
+			t.Errorf("Expected section %s to be computed", section.name)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package telemetry
+
+// securityDataNotTracked is reported for the security data that is not recorded. The failed
+// authentication attempts are only rate limited in memory per client address and the user
+// accounts are never locked, so neither can be computed from the store.
+const securityDataNotTracked = -1
+
+func computeSecurityTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	data.Security.AuthFailuresSinceLastRun = securityDataNotTracked
+	data.Security.LockedAccounts = securityDataNotTracked
+
+	return nil
+}
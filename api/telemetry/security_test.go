@@ -0,0 +1,20 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestComputeSecurityTelemetry(t *testing.T) {
+	data := &TelemetryData{}
+	err := computeSecurityTelemetry(newTestTelemetryJobContext(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Security.AuthFailuresSinceLastRun != securityDataNotTracked {
+		t.Errorf("Expected AuthFailuresSinceLastRun to be %d, but got %d", securityDataNotTracked, data.Security.AuthFailuresSinceLastRun)
+	}
+	if data.Security.LockedAccounts != securityDataNotTracked {
+		t.Errorf("Expected LockedAccounts to be %d, but got %d", securityDataNotTracked, data.Security.LockedAccounts)
+	}
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"reflect"
+)
+
+// EmptySections returns the names of the sections of the telemetry data whose content is empty,
+// i.e. every numeric field is zero, every boolean field is false and every string, slice and map
+// field is empty. An empty section usually denotes a missing service or a read failure.
+// Section names are the snake case names of the TelemetryData fields (e.g. endpoint).
+func (d *TelemetryData) EmptySections() []string {
+	sections := make([]string, 0)
+
+	value := reflect.ValueOf(d).Elem()
+	valueType := value.Type()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" || field.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		if isEmptyValue(value.Field(i)) {
+			sections = append(sections, toSnakeCase(field.Name))
+		}
+	}
+
+	return sections
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !isEmptyValue(value.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	default:
+		return value.IsZero()
+	}
+}
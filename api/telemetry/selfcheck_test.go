@@ -0,0 +1,38 @@
+package telemetry
+
+import (
+	"testing"
+)
+
+func TestEmptySections(t *testing.T) {
+	data := &TelemetryData{
+		TelemetryID: "id",
+		Endpoint: EndpointTelemetryData{
+			Count:                     2,
+			CloudProviderDistribution: map[string]int{},
+		},
+		Registry: RegistryTelemetryData{
+			Configurations: []RegistryConfigurationTelemetryData{},
+		},
+		Runtime: RuntimeTelemetryData{Version: "1.24.0"},
+		User: UserTelemetryData{
+			AuthMethodDistribution: map[string]int{},
+		},
+	}
+
+	sections := make(map[string]bool)
+	for _, section := range data.EmptySections() {
+		sections[section] = true
+	}
+
+	for _, section := range []string{"registry", "settings", "user"} {
+		if !sections[section] {
+			t.Errorf("Expected section %s to be reported as empty, but got %v", section, sections)
+		}
+	}
+	for _, section := range []string{"endpoint", "runtime"} {
+		if sections[section] {
+			t.Errorf("Expected section %s not to be reported as empty", section)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// PayloadFormatJSON represents a payload encoded as JSON
+	PayloadFormatJSON = "json"
+	// PayloadFormatProtobuf represents a payload encoded using the protobuf wire format
+	PayloadFormatProtobuf = "protobuf"
+
+	errInvalidResponseStatus = portainer.Error("Invalid response status (expecting 2xx)")
+	defaultSendTimeout       = 10
+	correlationIDHeader      = "X-Correlation-Id"
+)
+
+// sendTelemetry sends the data to the telemetry URL and records the size of the payload inside the result.
+// The data is encoded as JSON unless the protobuf format is selected and the data is a *TelemetryData.
+func (context *TelemetryJobContext) sendTelemetry(data interface{}, result *TelemetrySendResult) (err error) {
+	if context.PostSend != nil {
+		defer func() {
+			result.Sent = err == nil
+			result.Err = err
+			context.PostSend(result)
+		}()
+	}
+
+	if context.LogPayloadOnError {
+		defer func() {
+			if err != nil {
+				context.logPayload(data, err)
+			}
+		}()
+	}
+
+	payload, contentType, err := context.encodePayload(data)
+	if err != nil {
+		return err
+	}
+	result.RawBytes = len(payload)
+
+	body := payload
+	if context.CompressPayload {
+		body, err = compressPayload(payload)
+		if err != nil {
+			return err
+		}
+		result.CompressedBytes = len(body)
+		result.CompressionRatio = compressionRatio(result.RawBytes, result.CompressedBytes)
+	}
+
+	if context.CapturePayload {
+		context.lastPayload.set(body)
+	}
+
+	err = context.postPayload(body, contentType)
+	if err != nil {
+		return err
+	}
+
+	if context.CompressPayload {
+		context.logf("[INFO] [telemetry] [message: telemetry data sent] [raw_bytes: %d] [compressed_bytes: %d] [compression_ratio: %.2f]\n", result.RawBytes, result.CompressedBytes, result.CompressionRatio)
+	}
+
+	return nil
+}
+
+// postPayload posts the body to the telemetry URL. The body is expected to be gzip-compressed
+// when CompressPayload is enabled.
+func (context *TelemetryJobContext) postPayload(body []byte, contentType string) error {
+	client, requestURL := context.sendClients.get(context.destinationURL())
+
+	request, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request, cancel := withSendDeadline(request)
+	defer cancel()
+	request.Header.Set("Content-Type", contentType)
+	if context.CompressPayload {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if context.CorrelationID != "" {
+		request.Header.Set(correlationIDHeader, context.CorrelationID)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// The response is drained so that the connection can be reused by the next send
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errInvalidResponseStatus
+	}
+
+	return nil
+}
+
+// logPayload logs the data as indented JSON along with the error that occurred while sending it
+func (context *TelemetryJobContext) logPayload(data interface{}, sendErr error) {
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		context.logf("[ERROR] [telemetry] [message: unable to log telemetry payload] [err: %s]\n", err)
+		return
+	}
+
+	context.logf("[ERROR] [telemetry] [message: unable to send telemetry payload] [err: %s] [payload: %s]\n", sendErr, payload)
+}
+
+// encodePayload encodes the payload using the configured format. The encoding is deterministic,
+// the map keys are sorted by both encoders: the same data always produces the same payload.
+// JSON payloads are wrapped in an Envelope, protobuf payloads are sent as is.
+func (context *TelemetryJobContext) encodePayload(data interface{}) ([]byte, string, error) {
+	if telemetryData, ok := data.(*TelemetryData); ok && context.Format == PayloadFormatProtobuf {
+		payload, err := telemetryData.MarshalProtobuf()
+		return payload, protobufContentType, err
+	}
+
+	payload, err := marshalSchemaVersion(context.buildEnvelope(data), context.SchemaVersion)
+	return payload, "application/json", err
+}
+
+func compressPayload(payload []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// compressionRatio returns the ratio between the raw and the compressed size of a payload,
+// e.g. 4 means that the compressed payload is 4 times smaller than the raw payload.
+func compressionRatio(rawBytes, compressedBytes int) float64 {
+	if compressedBytes == 0 {
+		return 0
+	}
+	return float64(rawBytes) / float64(compressedBytes)
+}
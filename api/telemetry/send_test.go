@@ -0,0 +1,169 @@
+package telemetry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendTelemetryCompression(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received, _ = ioutil.ReadAll(reader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.CompressPayload = true
+
+	data := make([]string, 100)
+	for i := range data {
+		data[i] = "portainer"
+	}
+
+	result := &TelemetrySendResult{}
+	err := context.sendTelemetry(data, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.RawBytes != len(received) {
+		t.Errorf("Expected RawBytes to be %d, but it was %d instead", len(received), result.RawBytes)
+	}
+	if result.CompressedBytes == 0 || result.CompressedBytes >= result.RawBytes {
+		t.Errorf("Expected the payload to be compressed, but got %d compressed bytes for %d raw bytes", result.CompressedBytes, result.RawBytes)
+	}
+
+	expectedRatio := float64(result.RawBytes) / float64(result.CompressedBytes)
+	if result.CompressionRatio != expectedRatio {
+		t.Errorf("Expected CompressionRatio to be %f, but it was %f instead", expectedRatio, result.CompressionRatio)
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	if ratio := compressionRatio(1000, 250); ratio != 4 {
+		t.Errorf("Expected compression ratio to be 4, but it was %f instead", ratio)
+	}
+	if ratio := compressionRatio(1000, 0); ratio != 0 {
+		t.Errorf("Expected compression ratio to be 0, but it was %f instead", ratio)
+	}
+}
+
+func TestSendTelemetryLogPayloadOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+
+	err := context.sendTelemetry(sampleTelemetryData(), &TelemetrySendResult{})
+	if err == nil {
+		t.Fatal("Expected the send to fail")
+	}
+	if strings.Contains(logs.String(), "TelemetryID") {
+		t.Errorf("Expected the payload not to be logged when LogPayloadOnError is disabled")
+	}
+
+	context.LogPayloadOnError = true
+	err = context.sendTelemetry(sampleTelemetryData(), &TelemetrySendResult{})
+	if err == nil {
+		t.Fatal("Expected the send to fail")
+	}
+	if !strings.Contains(logs.String(), "\"TelemetryID\": \"5b2f0b0e-7c6a-4f4e-9d0e-0e9c1e4b7d0a\"") {
+		t.Errorf("Expected the indented payload to be logged, but got %s", logs.String())
+	}
+}
+
+func TestEncodePayloadDeterministic(t *testing.T) {
+	data := sampleTelemetryData()
+	data.User.AuthMethodDistribution = make(map[string]int)
+	for i := 0; i < 50; i++ {
+		data.Endpoint.CloudProviderDistribution[fmt.Sprintf("provider-%d", i)] = i
+		data.User.AuthMethodDistribution[fmt.Sprintf("method-%d", i)] = i
+	}
+
+	cases := []struct {
+		name          string
+		format        string
+		schemaVersion int
+	}{
+		{"JSON", PayloadFormatJSON, 0},
+		{"JSON with schema version", PayloadFormatJSON, 1},
+		{"Protobuf", PayloadFormatProtobuf, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			context := newTestTelemetryJobContext()
+			context.now = func() time.Time { return time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC) }
+			context.Format = c.format
+			context.SchemaVersion = c.schemaVersion
+
+			expected, _, err := context.encodePayload(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 10; i++ {
+				payload, _, err := context.encodePayload(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(payload, expected) {
+					t.Fatalf("Expected the payload to be identical across encodings")
+				}
+			}
+		})
+	}
+}
+
+func TestSendTelemetryPostSend(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		var results []*TelemetrySendResult
+		context := newTestTelemetryJobContext()
+		context.telemetryURL = server.URL
+		context.PostSend = func(result *TelemetrySendResult) {
+			results = append(results, result)
+		}
+		runner := NewTelemetryJobRunner(nil, context)
+
+		runner.RunWithResult()
+		server.Close()
+
+		expectedSent := status == http.StatusOK
+		if len(results) != 1 {
+			t.Fatalf("Expected PostSend to be called once, but it was called %d times", len(results))
+		}
+		if results[0].Sent != expectedSent {
+			t.Errorf("Expected PostSend to be called with Sent=%t, but got %t", expectedSent, results[0].Sent)
+		}
+		if (results[0].Err == nil) != expectedSent {
+			t.Errorf("Expected PostSend to be called with the send error, but got %v", results[0].Err)
+		}
+	}
+}
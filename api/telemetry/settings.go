@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"time"
+)
+
+// settingsNeverModified is reported as the age of the settings when they were never updated
+// since the modification date is recorded.
+const settingsNeverModified = -1
+
+// hostManagementUsageNotTracked is reported as the number of endpoints where the host management
+// features were used, the host browser and host management actions are not audited.
+const hostManagementUsageNotTracked = -1
+
+func computeSettingsTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	// The resource details are excluded unless the settings can be read and allow them
+	data.Settings.ResourceDetailsExcluded = true
+
+	settings, err := context.settings()
+	if err != nil {
+		return err
+	}
+	data.Settings.ResourceDetailsExcluded = settings.TelemetryExcludeResourceDetails
+
+	registries, err := context.registries()
+	if err != nil {
+		return err
+	}
+
+	data.Settings.PublicAccessEnabled = context.AuthenticationDisabled
+	data.Settings.HostManagementUsedEndpoints = hostManagementUsageNotTracked
+
+	data.Settings.SettingsLastModified = settingsNeverModified
+	if settings.LastModified > 0 {
+		data.Settings.SettingsLastModified = int64(context.now().Sub(time.Unix(settings.LastModified, 0)).Seconds())
+	}
+
+	// Only the presence of a custom CA certificate is reported, never its content
+	if settings.LDAPSettings.TLSConfig.TLSCACertPath != "" {
+		data.Settings.CustomCACertificates++
+	}
+
+	for _, registry := range registries {
+		if registry.ManagementConfiguration != nil && registry.ManagementConfiguration.TLSConfig.TLSCACertPath != "" {
+			data.Settings.CustomCACertificates++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeSettingsTelemetry(t *testing.T) {
+	t.Run("No custom CA certificate", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+			{ID: 1, Type: portainer.CustomRegistry},
+		}}
+
+		data := &TelemetryData{}
+		err := computeSettingsTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Settings.CustomCACertificates != 0 {
+			t.Errorf("Expected CustomCACertificates to be 0, but it was %d instead", data.Settings.CustomCACertificates)
+		}
+	})
+
+	t.Run("LDAP and registry custom CA certificates", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		settings := portainer.Settings{}
+		settings.LDAPSettings.TLSConfig.TLSCACertPath = "/data/tls/ldap/ca.pem"
+		context.settingsService = &fakeSettingsService{settings: settings}
+		context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+			{
+				ID:   1,
+				Type: portainer.CustomRegistry,
+				ManagementConfiguration: &portainer.RegistryManagementConfiguration{
+					TLSConfig: portainer.TLSConfiguration{TLS: true, TLSCACertPath: "/data/tls/registry_1/ca.pem"},
+				},
+			},
+		}}
+
+		data := &TelemetryData{}
+		err := computeSettingsTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Settings.CustomCACertificates != 2 {
+			t.Errorf("Expected CustomCACertificates to be 2, but it was %d instead", data.Settings.CustomCACertificates)
+		}
+	})
+}
+
+func TestComputeTelemetryWithExcludedResourceDetails(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.settingsService = &fakeSettingsService{settings: portainer.Settings{TelemetryExcludeResourceDetails: true}}
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment},
+	}}
+	context.teamService = &fakeTeamService{teams: []portainer.Team{{ID: 1, Name: "team"}}}
+
+	data, err := ComputeTelemetry(context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.Count != 0 || data.Endpoint.Endpoints != nil {
+		t.Errorf("Expected the endpoint section to be empty, but it was %+v", data.Endpoint)
+	}
+	if !data.Settings.ResourceDetailsExcluded {
+		t.Errorf("Expected the settings section to report the excluded resource details")
+	}
+	if data.Team.Count != 1 {
+		t.Errorf("Expected the team section to be computed, but Count was %d", data.Team.Count)
+	}
+}
+
+func TestComputeTelemetryWithUnreadableSettings(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.settingsService = &fakeSettingsService{err: errors.New("settings unavailable")}
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment},
+	}}
+
+	data, sectionErrors, err := computeTelemetry(context, newRetryBudget(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sectionErrors) == 0 || sectionErrors[0].Section != SectionSettings {
+		t.Errorf("Expected the settings section to fail, but got %v", sectionErrors)
+	}
+	if !data.Settings.ResourceDetailsExcluded {
+		t.Errorf("Expected the resource details to be excluded when the settings cannot be read")
+	}
+	if data.Endpoint.Count != 0 || data.Endpoint.Endpoints != nil {
+		t.Errorf("Expected the endpoint section to be empty, but it was %+v", data.Endpoint)
+	}
+}
+
+func TestComputeSettingsPublicAccessTelemetry(t *testing.T) {
+	for _, authenticationDisabled := range []bool{false, true} {
+		context := newTestTelemetryJobContext()
+		context.AuthenticationDisabled = authenticationDisabled
+
+		data := &TelemetryData{}
+		err := computeSettingsTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Settings.PublicAccessEnabled != authenticationDisabled {
+			t.Errorf("Expected PublicAccessEnabled to be %t, but it was %t instead", authenticationDisabled, data.Settings.PublicAccessEnabled)
+		}
+	}
+}
+
+func TestComputeSettingsHostManagementUsageTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+
+	data := &TelemetryData{}
+	err := computeSettingsTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Settings.HostManagementUsedEndpoints != hostManagementUsageNotTracked {
+		t.Errorf("Expected HostManagementUsedEndpoints to be %d, but it was %d instead", hostManagementUsageNotTracked, data.Settings.HostManagementUsedEndpoints)
+	}
+}
+
+func TestComputeSettingsLastModifiedTelemetry(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	context := newTestTelemetryJobContext()
+	context.now = func() time.Time { return now }
+
+	data := &TelemetryData{}
+	err := computeSettingsTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Settings.SettingsLastModified != settingsNeverModified {
+		t.Errorf("Expected SettingsLastModified to be %d, but it was %d instead", settingsNeverModified, data.Settings.SettingsLastModified)
+	}
+
+	context.settingsService = &fakeSettingsService{settings: portainer.Settings{LastModified: now.Add(-2 * time.Hour).Unix()}}
+
+	data = &TelemetryData{}
+	err = computeSettingsTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Settings.SettingsLastModified != 7200 {
+		t.Errorf("Expected SettingsLastModified to be 7200, but it was %d instead", data.Settings.SettingsLastModified)
+	}
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+// sheddableSections lists the optional parts of the telemetry data, in the order in which they are
+// removed when the payload exceeds MaxPayloadBytes. The aggregate counts are never removed.
+var sheddableSections = []struct {
+	name string
+	shed func(data *TelemetryData)
+}{
+	{"endpoint.endpoints", func(data *TelemetryData) { data.Endpoint.Endpoints = []EndpointEnvironmentTelemetryData{} }},
+	{"registry.configurations", func(data *TelemetryData) { data.Registry.Configurations = []RegistryConfigurationTelemetryData{} }},
+	{"endpoint.cloud_provider_distribution", func(data *TelemetryData) { data.Endpoint.CloudProviderDistribution = map[string]int{} }},
+	{"endpoint.docker_edition_distribution", func(data *TelemetryData) { data.Endpoint.DockerEditionDistribution = map[string]int{} }},
+	{"registry.stacks_per_registry", func(data *TelemetryData) { data.Registry.StacksPerRegistry = map[string]int{} }},
+	{"user.auth_method_distribution", func(data *TelemetryData) { data.User.AuthMethodDistribution = map[string]int{} }},
+	{"edge_compute.schedule.job_type_counts", func(data *TelemetryData) { data.EdgeCompute.Schedule.JobTypeCounts = map[string]int{} }},
+}
+
+// shedPayload removes the optional parts of the telemetry data, following the order of sheddableSections,
+// until the encoded payload fits in MaxPayloadBytes. The removed parts are listed in SheddedSections.
+// The data is returned as is when MaxPayloadBytes is not set, and returned with every optional part
+// removed when it still does not fit.
+func (context *TelemetryJobContext) shedPayload(data *TelemetryData) (*TelemetryData, error) {
+	if context.MaxPayloadBytes <= 0 {
+		return data, nil
+	}
+
+	shed := *data
+	shed.SheddedSections = nil
+
+	for _, section := range sheddableSections {
+		payload, _, err := context.encodePayload(&shed)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) <= context.MaxPayloadBytes {
+			return &shed, nil
+		}
+
+		section.shed(&shed)
+		shed.SheddedSections = append(shed.SheddedSections, section.name)
+	}
+
+	payload, _, err := context.encodePayload(&shed)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > context.MaxPayloadBytes {
+		context.logf("[WARN] [telemetry] [message: telemetry payload exceeds the maximum size after shedding every optional section] [payload_bytes: %d] [max_payload_bytes: %d]\n", len(payload), context.MaxPayloadBytes)
+	}
+
+	return &shed, nil
+}
@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func oversizedTelemetryData() *TelemetryData {
+	data := &TelemetryData{
+		TelemetryID: "id",
+		Endpoint: EndpointTelemetryData{
+			Count:                     1000,
+			Endpoints:                 make([]EndpointEnvironmentTelemetryData, 1000),
+			CloudProviderDistribution: make(map[string]int),
+		},
+		Registry: RegistryTelemetryData{
+			Count:          100,
+			Configurations: make([]RegistryConfigurationTelemetryData, 100),
+		},
+	}
+
+	for i := range data.Endpoint.Endpoints {
+		data.Endpoint.Endpoints[i] = EndpointEnvironmentTelemetryData{Type: EndpointTypeAgent, CloudProvider: CloudProviderUnknown}
+	}
+	for i := 0; i < 200; i++ {
+		data.Endpoint.CloudProviderDistribution[fmt.Sprintf("provider-%d", i)] = i
+	}
+
+	return data
+}
+
+func TestShedPayload(t *testing.T) {
+	data := oversizedTelemetryData()
+
+	context := newTestTelemetryJobContext()
+	context.MaxPayloadBytes = 8192
+
+	shed, err := context.shedPayload(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, _, err := context.encodePayload(shed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload) > context.MaxPayloadBytes {
+		t.Errorf("Expected the payload to fit in %d bytes, but it was %d bytes", context.MaxPayloadBytes, len(payload))
+	}
+
+	expected := []string{"endpoint.endpoints", "registry.configurations"}
+	if !reflect.DeepEqual(shed.SheddedSections, expected) {
+		t.Errorf("Expected the shedded sections to be %v, but got %v", expected, shed.SheddedSections)
+	}
+	// The shedded sections are sent empty, as when there is nothing to report
+	for _, section := range []string{`"Endpoints":[]`, `"Configurations":[]`} {
+		if !strings.Contains(string(payload), section) {
+			t.Errorf("Expected the payload to contain %s", section)
+		}
+	}
+	if len(shed.Endpoint.CloudProviderDistribution) != 200 {
+		t.Errorf("Expected the cloud provider distribution to be kept")
+	}
+	if shed.Endpoint.Count != 1000 || shed.Registry.Count != 100 {
+		t.Errorf("Expected the aggregate counts to be kept, but got %d endpoints and %d registries", shed.Endpoint.Count, shed.Registry.Count)
+	}
+	if len(data.Endpoint.Endpoints) != 1000 {
+		t.Errorf("Expected the original data not to be modified")
+	}
+}
+
+func TestShedPayloadDisabled(t *testing.T) {
+	data := oversizedTelemetryData()
+
+	context := newTestTelemetryJobContext()
+
+	shed, err := context.shedPayload(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if shed != data {
+		t.Errorf("Expected the data to be returned as is when MaxPayloadBytes is not set")
+	}
+}
@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"encoding/json"
+
+	"github.com/portainer/portainer/api"
+)
+
+// snapshotEngineInfo represents the subset of the Docker engine information stored
+// inside a snapshot that is used by the telemetry job.
+type snapshotEngineInfo struct {
+	Name            string   `json:"Name"`
+	KernelVersion   string   `json:"KernelVersion"`
+	OperatingSystem string   `json:"OperatingSystem"`
+	Labels          []string `json:"Labels"`
+	RegistryConfig  struct {
+		Mirrors []string `json:"Mirrors"`
+	} `json:"RegistryConfig"`
+}
+
+// decodeSnapshotRaw decodes a raw snapshot value into target. Raw snapshot values are stored
+// as returned by the Docker API and are decoded as generic maps when read from the database,
+// a JSON round-trip is used to support both representations.
+func decodeSnapshotRaw(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// sanitizeSnapshot clamps the negative counts of a snapshot to zero.
+// It returns true when the snapshot contained at least one negative count.
+func sanitizeSnapshot(snapshot *portainer.Snapshot) bool {
+	corrupt := false
+
+	counts := []*int{
+		&snapshot.TotalCPU,
+		&snapshot.RunningContainerCount,
+		&snapshot.StoppedContainerCount,
+		&snapshot.HealthyContainerCount,
+		&snapshot.UnhealthyContainerCount,
+		&snapshot.VolumeCount,
+		&snapshot.ImageCount,
+		&snapshot.ServiceCount,
+		&snapshot.StackCount,
+	}
+	for _, count := range counts {
+		if *count < 0 {
+			*count = 0
+			corrupt = true
+		}
+	}
+
+	if snapshot.TotalMemory < 0 {
+		snapshot.TotalMemory = 0
+		corrupt = true
+	}
+
+	return corrupt
+}
+
+// sanitizeEndpointSnapshot sanitizes the latest snapshot of an endpoint. The snapshots are copied
+// so that the endpoint returned by the endpoint service is left untouched.
+// It returns true when the latest snapshot was corrupt.
+func sanitizeEndpointSnapshot(endpoint *portainer.Endpoint) bool {
+	if len(endpoint.Snapshots) == 0 {
+		return false
+	}
+
+	snapshots := make([]portainer.Snapshot, len(endpoint.Snapshots))
+	copy(snapshots, endpoint.Snapshots)
+	endpoint.Snapshots = snapshots
+
+	return sanitizeSnapshot(&endpoint.Snapshots[0])
+}
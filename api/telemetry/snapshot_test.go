@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestSanitizeSnapshot(t *testing.T) {
+	snapshot := portainer.Snapshot{
+		TotalCPU:              4,
+		TotalMemory:           -1024,
+		RunningContainerCount: -3,
+		StoppedContainerCount: 2,
+		ImageCount:            -1,
+	}
+
+	if !sanitizeSnapshot(&snapshot) {
+		t.Fatal("Expected the snapshot to be flagged as corrupt")
+	}
+
+	expected := portainer.Snapshot{TotalCPU: 4, StoppedContainerCount: 2}
+	if snapshot != expected {
+		t.Errorf("Expected the negative counts to be clamped, but got %+v", snapshot)
+	}
+
+	if sanitizeSnapshot(&snapshot) {
+		t.Error("Expected the sanitized snapshot not to be flagged as corrupt")
+	}
+}
+
+func TestComputeEndpointCorruptSnapshotTelemetry(t *testing.T) {
+	endpoints := []portainer.Endpoint{
+		{ID: 1, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{RunningContainerCount: -5}}},
+		{ID: 2, Type: portainer.DockerEnvironment, Snapshots: []portainer.Snapshot{{RunningContainerCount: 5}}},
+		{ID: 3, Type: portainer.DockerEnvironment},
+	}
+
+	context := newTestTelemetryJobContext()
+	context.endpointService = &fakeEndpointService{endpoints: endpoints}
+
+	data := &TelemetryData{}
+	err := computeEndpointTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Endpoint.CorruptSnapshotCount != 1 {
+		t.Errorf("Expected CorruptSnapshotCount to be 1, but it was %d instead", data.Endpoint.CorruptSnapshotCount)
+	}
+	if endpoints[0].Snapshots[0].RunningContainerCount != -5 {
+		t.Errorf("Expected the endpoint snapshots not to be modified")
+	}
+}
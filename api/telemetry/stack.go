@@ -0,0 +1,192 @@
+package telemetry
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/portainer/portainer/api"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultLargeStackServiceThreshold is the default number of services above which a stack is large
+const defaultLargeStackServiceThreshold = 20
+
+// composeFile represents the subset of a Compose file used by the telemetry job
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+// composeService represents the subset of a Compose service definition used by the telemetry job.
+// Secrets and configs support both the short and the long syntax.
+type composeService struct {
+	Image   string        `yaml:"image"`
+	Secrets []interface{} `yaml:"secrets"`
+	Configs []interface{} `yaml:"configs"`
+}
+
+// composeFileCache holds the Compose files of the stacks parsed during a run, so that each file
+// is read and parsed once whatever the number of sections using it.
+type composeFileCache struct {
+	mu    sync.Mutex
+	files map[string]composeFileEntry
+}
+
+type composeFileEntry struct {
+	compose *composeFile
+	err     error
+}
+
+// stackComposeFile returns the parsed Compose file of a stack. An error is returned when the file
+// cannot be read or is malformed. The file is parsed once per run when the context holds a cache.
+func (context *TelemetryJobContext) stackComposeFile(stack *portainer.Stack) (*composeFile, error) {
+	filePath := path.Join(stack.ProjectPath, stack.EntryPoint)
+
+	cache := context.composeFiles
+	if cache == nil {
+		return readComposeFile(context.fileService, filePath)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.files == nil {
+		cache.files = make(map[string]composeFileEntry)
+	}
+
+	entry, ok := cache.files[filePath]
+	if !ok {
+		entry.compose, entry.err = readComposeFile(context.fileService, filePath)
+		cache.files[filePath] = entry
+	}
+
+	return entry.compose, entry.err
+}
+
+func readComposeFile(fileService portainer.FileService, filePath string) (*composeFile, error) {
+	content, err := fileService.GetFileContent(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose composeFile
+	err = yaml.Unmarshal(content, &compose)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compose, nil
+}
+
+func computeStackTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	stacks, err := context.stacks()
+	if err != nil {
+		return err
+	}
+
+	resourceControls, err := context.resourceControls()
+	if err != nil {
+		return err
+	}
+
+	stackResourceControls := make(map[string]portainer.ResourceControl)
+	for _, resourceControl := range resourceControls {
+		if resourceControl.Type == portainer.StackResourceControl {
+			stackResourceControls[resourceControl.ResourceID] = resourceControl
+		}
+	}
+
+	data.Stack.Count = len(stacks)
+	now := context.now()
+
+	for _, stack := range stacks {
+		computeStackCreationTelemetry(&stack, now, data)
+
+		if resourceControl, ok := stackResourceControls[stack.Name]; ok {
+			computeStackOwnershipTelemetry(&resourceControl, data)
+		}
+
+		if isBrokenFileStack(context, &stack) {
+			data.Stack.BrokenFileStacks++
+			continue
+		}
+
+		// Unreadable and malformed Compose files are skipped
+		compose, err := context.stackComposeFile(&stack)
+		if err != nil {
+			continue
+		}
+
+		if len(compose.Services) > context.LargeStackServiceThreshold {
+			data.Stack.LargeStackCount++
+		}
+
+		hasSecrets, hasConfigs := false, false
+		for _, service := range compose.Services {
+			if len(service.Secrets) > 0 {
+				hasSecrets = true
+			}
+			if len(service.Configs) > 0 {
+				hasConfigs = true
+			}
+		}
+
+		if hasSecrets {
+			data.Stack.StacksWithSecrets++
+		}
+		if hasConfigs {
+			data.Stack.StacksWithConfigs++
+		}
+	}
+
+	return nil
+}
+
+// computeStackOwnershipTelemetry counts a stack as public when its resource control is public or
+// restricted to the administrators, otherwise as owned by teams and/or users depending on the
+// accesses of the resource control.
+func computeStackOwnershipTelemetry(resourceControl *portainer.ResourceControl, data *TelemetryData) {
+	if resourceControl.Public || resourceControl.AdministratorsOnly {
+		data.Stack.PublicStacks++
+		return
+	}
+
+	if len(resourceControl.TeamAccesses) > 0 {
+		data.Stack.TeamOwnedStacks++
+	}
+
+	if len(resourceControl.UserAccesses) > 0 {
+		data.Stack.UserOwnedStacks++
+	}
+}
+
+// isBrokenFileStack returns true when the Compose file of the stack no longer exists on disk.
+// Stacks without project path and file existence checks that fail are never reported as broken.
+func isBrokenFileStack(context *TelemetryJobContext, stack *portainer.Stack) bool {
+	if stack.ProjectPath == "" {
+		return false
+	}
+
+	exists, err := context.fileService.FileExists(path.Join(stack.ProjectPath, stack.EntryPoint))
+	if err != nil {
+		return false
+	}
+
+	return !exists
+}
+
+// computeStackCreationTelemetry counts the stacks created in the last 7 and 30 days.
+// Stacks created before the creation date was recorded are not taken into account.
+func computeStackCreationTelemetry(stack *portainer.Stack, now time.Time, data *TelemetryData) {
+	if stack.Created == 0 {
+		return
+	}
+
+	age := now.Sub(time.Unix(stack.Created, 0))
+	if age <= 7*24*time.Hour {
+		data.Stack.StacksCreatedLast7Days++
+	}
+	if age <= 30*24*time.Hour {
+		data.Stack.StacksCreatedLast30Days++
+	}
+}
@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/portainer/portainer/api"
+)
+
+// computeStacksPerRegistry counts, for each type of registry, the stacks referencing at least one image
+// hosted on a configured registry of that type. The stacks that do not reference any configured
+// registry, or whose file cannot be read or parsed, are counted under StackRegistryUnknown.
+func computeStacksPerRegistry(context *TelemetryJobContext, registries []portainer.Registry, data *TelemetryData) error {
+	stacks, err := context.stacks()
+	if err != nil {
+		return err
+	}
+
+	data.Registry.StacksPerRegistry = make(map[string]int)
+
+	for _, stack := range stacks {
+		compose, err := context.stackComposeFile(&stack)
+		if err != nil {
+			data.Registry.StacksPerRegistry[StackRegistryUnknown]++
+			continue
+		}
+
+		registryTypes := make(map[string]bool)
+		for _, image := range stackImages(compose) {
+			for _, registry := range registries {
+				if imageMatchesRegistry(image, &registry) {
+					registryTypes[RegistryTypeTelemetry(registry.Type)] = true
+				}
+			}
+		}
+
+		if len(registryTypes) == 0 {
+			data.Registry.StacksPerRegistry[StackRegistryUnknown]++
+			continue
+		}
+
+		for registryType := range registryTypes {
+			data.Registry.StacksPerRegistry[registryType]++
+		}
+	}
+
+	return nil
+}
+
+// computeUnusedRegistries counts the registries that are not referenced by any image of any stack.
+// Usage is determined on a best-effort basis: the registries without URL are never counted as unused,
+// and nothing is counted when the file of a stack cannot be read or parsed.
+// Images pulled by containers deployed outside of stacks are not taken into account.
+func computeUnusedRegistries(context *TelemetryJobContext, registries []portainer.Registry, data *TelemetryData) error {
+	stacks, err := context.stacks()
+	if err != nil {
+		return err
+	}
+
+	used := make([]bool, len(registries))
+
+	for _, stack := range stacks {
+		compose, err := context.stackComposeFile(&stack)
+		if err != nil {
+			data.Registry.UnusedCount = unusedRegistriesUnknown
+			return nil
+		}
+
+		for _, image := range stackImages(compose) {
+			for i := range registries {
+				if imageMatchesRegistry(image, &registries[i]) {
+					used[i] = true
+				}
+			}
+		}
+	}
+
+	for i, registry := range registries {
+		if !used[i] && registry.URL != "" {
+			data.Registry.UnusedCount++
+		}
+	}
+
+	return nil
+}
+
+// stackImages returns the image references declared by the services of a Compose file
+func stackImages(compose *composeFile) []string {
+	images := make([]string, 0, len(compose.Services))
+	for _, service := range compose.Services {
+		if service.Image != "" {
+			images = append(images, service.Image)
+		}
+	}
+	return images
+}
+
+// imageMatchesRegistry returns true when the image reference is hosted on the registry
+func imageMatchesRegistry(image string, registry *portainer.Registry) bool {
+	registryURL := strings.TrimPrefix(strings.TrimPrefix(registry.URL, "https://"), "http://")
+	registryURL = strings.TrimSuffix(registryURL, "/")
+	if registryURL == "" {
+		return false
+	}
+
+	return strings.HasPrefix(image, registryURL+"/")
+}
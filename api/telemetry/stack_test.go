@@ -0,0 +1,179 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeStackTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/2", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/3", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/4", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3.7'\nservices:\n  web:\n    image: nginx\n    secrets:\n      - source: site_key\n        target: /run/secrets/key\n    configs:\n      - site_config\n",
+		"/data/compose/2/docker-compose.yml": "version: '3.7'\nservices:\n  web:\n    image: nginx\n",
+		"/data/compose/3/docker-compose.yml": "services: [\n  web: {",
+	}}
+
+	data := &TelemetryData{}
+	err := computeStackTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stack.Count != 4 {
+		t.Errorf("Expected 4 stacks, but got %d", data.Stack.Count)
+	}
+	if data.Stack.StacksWithSecrets != 1 {
+		t.Errorf("Expected 1 stack with secrets, but got %d", data.Stack.StacksWithSecrets)
+	}
+	if data.Stack.StacksWithConfigs != 1 {
+		t.Errorf("Expected 1 stack with configs, but got %d", data.Stack.StacksWithConfigs)
+	}
+}
+
+func TestComputeBrokenFileStackTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/2", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3'\nservices:\n  web:\n    image: nginx\n",
+	}}
+
+	data := &TelemetryData{}
+	err := computeStackTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stack.BrokenFileStacks != 1 {
+		t.Errorf("Expected 1 stack with a missing Compose file, but got %d", data.Stack.BrokenFileStacks)
+	}
+}
+
+func TestComputeLargeStackTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.LargeStackServiceThreshold = 2
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+		{ProjectPath: "/data/compose/2", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3'\nservices:\n  web:\n    image: nginx\n",
+		"/data/compose/2/docker-compose.yml": "version: '3'\nservices:\n  web:\n    image: nginx\n  api:\n    image: api\n  db:\n    image: postgres\n",
+	}}
+
+	data := &TelemetryData{}
+	err := computeStackTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stack.LargeStackCount != 1 {
+		t.Errorf("Expected 1 large stack, but got %d", data.Stack.LargeStackCount)
+	}
+}
+
+func TestComputeStackOwnershipTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ID: 1, Name: "team-stack"},
+		{ID: 2, Name: "user-stack"},
+		{ID: 3, Name: "public-stack"},
+		{ID: 4, Name: "uncontrolled-stack"},
+	}}
+	context.resourceControlService = &fakeResourceControlService{resourceControls: []portainer.ResourceControl{
+		{ID: 1, ResourceID: "team-stack", Type: portainer.StackResourceControl, TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 1}}},
+		{ID: 2, ResourceID: "user-stack", Type: portainer.StackResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+		{ID: 3, ResourceID: "public-stack", Type: portainer.StackResourceControl, Public: true},
+		{ID: 4, ResourceID: "uncontrolled-stack", Type: portainer.ServiceResourceControl, Public: true},
+	}}
+
+	data := &TelemetryData{}
+	err := computeStackTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stack.TeamOwnedStacks != 1 || data.Stack.UserOwnedStacks != 1 || data.Stack.PublicStacks != 1 {
+		t.Errorf("Expected 1 team owned, 1 user owned and 1 public stack, but got %d, %d and %d", data.Stack.TeamOwnedStacks, data.Stack.UserOwnedStacks, data.Stack.PublicStacks)
+	}
+}
+
+func TestComputeStackCreationTelemetry(t *testing.T) {
+	now := time.Date(2020, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	context := newTestTelemetryJobContext()
+	context.now = func() time.Time { return now }
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ID: 1, Created: now.Add(-24 * time.Hour).Unix()},
+		{ID: 2, Created: now.Add(-10 * 24 * time.Hour).Unix()},
+		{ID: 3, Created: now.Add(-60 * 24 * time.Hour).Unix()},
+		{ID: 4},
+	}}
+
+	data := &TelemetryData{}
+	err := computeStackTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stack.StacksCreatedLast7Days != 1 {
+		t.Errorf("Expected 1 stack created in the last 7 days, but got %d", data.Stack.StacksCreatedLast7Days)
+	}
+	if data.Stack.StacksCreatedLast30Days != 2 {
+		t.Errorf("Expected 2 stacks created in the last 30 days, but got %d", data.Stack.StacksCreatedLast30Days)
+	}
+}
+
+func TestStackComposeFileParsedOncePerRun(t *testing.T) {
+	fileService := &fakeFileService{files: map[string]string{
+		"/data/compose/1/docker-compose.yml": "version: '3'\nx-image: &image \"registry.example.com:5000/app/web:1.0\"\nservices:\n  web:\n    image: *image\n  worker:\n    image: 'registry.example.com:5000/app/worker:1.0' # pinned\n",
+	}}
+
+	context := newTestTelemetryJobContext()
+	context.ComputeStacksPerRegistry = true
+	context.ComputeUnusedRegistries = true
+	context.registryService = &fakeRegistryService{registries: []portainer.Registry{
+		{Type: portainer.CustomRegistry, URL: "registry.example.com:5000"},
+	}}
+	context.stackService = &fakeStackService{stacks: []portainer.Stack{
+		{ProjectPath: "/data/compose/1", EntryPoint: "docker-compose.yml"},
+	}}
+	context.fileService = fileService
+
+	data, sectionErrors, err := computeTelemetry(context, newRetryBudget(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sectionErrors) > 0 {
+		t.Fatalf("Expected no section error, but got %v", sectionErrors)
+	}
+
+	if fileService.reads != 1 {
+		t.Errorf("Expected the Compose file to be read once, but it was read %d times", fileService.reads)
+	}
+	if data.Registry.StacksPerRegistry[RegistryConfigurationTypeCustom] != 1 || data.Registry.UnusedCount != 0 {
+		t.Errorf("Expected the anchored and quoted images to reference the registry, but got %v and %d unused registries", data.Registry.StacksPerRegistry, data.Registry.UnusedCount)
+	}
+}
+
+func TestStackImages(t *testing.T) {
+	compose := &composeFile{Services: map[string]composeService{
+		"web":    {Image: "nginx:latest"},
+		"worker": {},
+	}}
+
+	images := stackImages(compose)
+	if len(images) != 1 || images[0] != "nginx:latest" {
+		t.Errorf("Expected the images of the services to be returned, but got %v", images)
+	}
+}
@@ -0,0 +1,213 @@
+package telemetry
+
+import (
+	"context"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	errStoreFileUnavailable = portainer.Error("Files are not available when computing from a store")
+	errUnknownSection       = portainer.Error("Unknown telemetry section")
+)
+
+// TelemetryDataStore represents the data required to compute the telemetry data
+type TelemetryDataStore interface {
+	Endpoints() ([]portainer.Endpoint, error)
+	Registries() ([]portainer.Registry, error)
+	ResourceControls() ([]portainer.ResourceControl, error)
+	Schedules() ([]portainer.Schedule, error)
+	Settings() (*portainer.Settings, error)
+	Stacks() ([]portainer.Stack, error)
+	Teams() ([]portainer.Team, error)
+	TeamMemberships() ([]portainer.TeamMembership, error)
+	TelemetryConfiguration() (*portainer.TelemetryConfiguration, error)
+	Users() ([]portainer.User, error)
+	Webhooks() ([]portainer.Webhook, error)
+}
+
+// ComputeFromStore computes the telemetry data from a store, independently of any schedule.
+// It can be used to compute the telemetry of a database backup opened read-only: the store is
+// never written to, a missing telemetry identifier is generated but not persisted.
+// The stack files are not available and the Edge agent connections are not computed.
+// It returns a *SectionError if any of the sections cannot be computed.
+func ComputeFromStore(store TelemetryDataStore) (*TelemetryData, error) {
+	return ComputeTelemetry(newStoreTelemetryJobContext(store))
+}
+
+// ComputeSection computes a single section of the telemetry data from a store, e.g. SectionEndpoint,
+// and stores the result in data. The other sections of data are left untouched, a section disabled
+// by the settings of data is not computed. The sections are not cancellable once started, ctx is only
+// checked before the computation.
+// It returns a *SectionError if the section is unknown or cannot be computed.
+func ComputeSection(ctx context.Context, section string, data *TelemetryData, store TelemetryDataStore) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	for _, registered := range telemetrySections {
+		if registered.name != section {
+			continue
+		}
+
+		jobContext := newStoreTelemetryJobContext(store)
+		if registered.skip != nil && registered.skip(jobContext, data) {
+			return nil
+		}
+
+		sectionError := jobContext.computeSection(section, func() error {
+			return registered.compute(jobContext, data)
+		})
+		if sectionError != nil {
+			return sectionError
+		}
+		return nil
+	}
+
+	return &SectionError{Section: section, Err: errUnknownSection}
+}
+
+func newStoreTelemetryJobContext(store TelemetryDataStore) *TelemetryJobContext {
+	return NewTelemetryJobContext(
+		&storeEndpointService{store: store},
+		&storeRegistryService{store: store},
+		&storeSettingsService{store: store},
+		&storeTeamService{store: store},
+		&storeTeamMembershipService{store: store},
+		&storeUserService{store: store},
+		&storeStackService{store: store},
+		&storeWebhookService{store: store},
+		&storeResourceControlService{store: store},
+		&storeScheduleService{store: store},
+		&unavailableFileService{},
+		nil,
+		&storeTelemetryService{store: store},
+		"",
+	)
+}
+
+type storeEndpointService struct {
+	portainer.EndpointService
+	store TelemetryDataStore
+}
+
+func (service *storeEndpointService) Endpoints() ([]portainer.Endpoint, error) {
+	return service.store.Endpoints()
+}
+
+type storeRegistryService struct {
+	portainer.RegistryService
+	store TelemetryDataStore
+}
+
+func (service *storeRegistryService) Registries() ([]portainer.Registry, error) {
+	return service.store.Registries()
+}
+
+type storeScheduleService struct {
+	portainer.ScheduleService
+	store TelemetryDataStore
+}
+
+func (service *storeScheduleService) Schedules() ([]portainer.Schedule, error) {
+	return service.store.Schedules()
+}
+
+type storeResourceControlService struct {
+	portainer.ResourceControlService
+	store TelemetryDataStore
+}
+
+func (service *storeResourceControlService) ResourceControls() ([]portainer.ResourceControl, error) {
+	return service.store.ResourceControls()
+}
+
+type storeSettingsService struct {
+	portainer.SettingsService
+	store TelemetryDataStore
+}
+
+func (service *storeSettingsService) Settings() (*portainer.Settings, error) {
+	return service.store.Settings()
+}
+
+type storeStackService struct {
+	portainer.StackService
+	store TelemetryDataStore
+}
+
+func (service *storeStackService) Stacks() ([]portainer.Stack, error) {
+	return service.store.Stacks()
+}
+
+type storeTeamService struct {
+	portainer.TeamService
+	store TelemetryDataStore
+}
+
+func (service *storeTeamService) Teams() ([]portainer.Team, error) {
+	return service.store.Teams()
+}
+
+type storeTeamMembershipService struct {
+	portainer.TeamMembershipService
+	store TelemetryDataStore
+}
+
+func (service *storeTeamMembershipService) TeamMemberships() ([]portainer.TeamMembership, error) {
+	return service.store.TeamMemberships()
+}
+
+type storeUserService struct {
+	portainer.UserService
+	store TelemetryDataStore
+}
+
+func (service *storeUserService) Users() ([]portainer.User, error) {
+	return service.store.Users()
+}
+
+type storeWebhookService struct {
+	portainer.WebhookService
+	store TelemetryDataStore
+}
+
+func (service *storeWebhookService) Webhooks() ([]portainer.Webhook, error) {
+	return service.store.Webhooks()
+}
+
+// storeTelemetryService keeps the telemetry configuration updates in memory
+type storeTelemetryService struct {
+	store         TelemetryDataStore
+	configuration *portainer.TelemetryConfiguration
+}
+
+func (service *storeTelemetryService) Configuration() (*portainer.TelemetryConfiguration, error) {
+	if service.configuration != nil {
+		configuration := *service.configuration
+		return &configuration, nil
+	}
+	return service.store.TelemetryConfiguration()
+}
+
+func (service *storeTelemetryService) UpdateConfiguration(configuration *portainer.TelemetryConfiguration) error {
+	service.configuration = configuration
+	return nil
+}
+
+func (service *storeTelemetryService) UpdateLastRun(state portainer.TelemetryRunState) error {
+	return nil
+}
+
+type unavailableFileService struct {
+	portainer.FileService
+}
+
+func (service *unavailableFileService) GetFileContent(filePath string) ([]byte, error) {
+	return nil, errStoreFileUnavailable
+}
+
+func (service *unavailableFileService) FileExists(filePath string) (bool, error) {
+	return false, errStoreFileUnavailable
+}
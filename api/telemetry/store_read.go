@@ -0,0 +1,139 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+const errStoreReadTimeout = portainer.Error("Store read timeout exceeded")
+
+// storeReadResult holds the outcome of a read executed by storeRead
+type storeReadResult struct {
+	value interface{}
+	err   error
+}
+
+// storeRead executes a read against the store and returns its result. When StoreReadTimeout is set,
+// the read is executed in a separate goroutine and errStoreReadTimeout is returned if it does not
+// complete in time, e.g. when the database is held by a long write transaction. The section is then
+// skipped. The goroutine only sends its result on a buffered channel, so that a read completing after
+// the timeout does not block nor write any variable of the caller.
+func (context *TelemetryJobContext) storeRead(read func() (interface{}, error)) (interface{}, error) {
+	if context.StoreReadTimeout <= 0 {
+		return read()
+	}
+
+	done := make(chan storeReadResult, 1)
+	go func() {
+		value, err := read()
+		done <- storeReadResult{value: value, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-time.After(context.StoreReadTimeout):
+		return nil, errStoreReadTimeout
+	}
+}
+
+func (context *TelemetryJobContext) endpoints() ([]portainer.Endpoint, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.endpointService.Endpoints()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Endpoint), nil
+}
+
+func (context *TelemetryJobContext) registries() ([]portainer.Registry, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.registryService.Registries()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Registry), nil
+}
+
+func (context *TelemetryJobContext) settings() (*portainer.Settings, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.settingsService.Settings()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*portainer.Settings), nil
+}
+
+func (context *TelemetryJobContext) stacks() ([]portainer.Stack, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.stackService.Stacks()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Stack), nil
+}
+
+func (context *TelemetryJobContext) teams() ([]portainer.Team, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.teamService.Teams()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Team), nil
+}
+
+func (context *TelemetryJobContext) teamMemberships() ([]portainer.TeamMembership, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.teamMembershipService.TeamMemberships()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.TeamMembership), nil
+}
+
+func (context *TelemetryJobContext) webhooks() ([]portainer.Webhook, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.webhookService.Webhooks()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Webhook), nil
+}
+
+func (context *TelemetryJobContext) resourceControls() ([]portainer.ResourceControl, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.resourceControlService.ResourceControls()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.ResourceControl), nil
+}
+
+func (context *TelemetryJobContext) schedules() ([]portainer.Schedule, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.scheduleService.Schedules()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.Schedule), nil
+}
+
+func (context *TelemetryJobContext) users() ([]portainer.User, error) {
+	value, err := context.storeRead(func() (interface{}, error) {
+		return context.userService.Users()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]portainer.User), nil
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+type blockedTeamService struct {
+	portainer.TeamService
+	release chan struct{}
+}
+
+func (service *blockedTeamService) Teams() ([]portainer.Team, error) {
+	<-service.release
+	return []portainer.Team{{ID: 1}}, nil
+}
+
+func TestComputeTelemetryStoreReadTimeout(t *testing.T) {
+	teamService := &blockedTeamService{release: make(chan struct{})}
+	defer close(teamService.release)
+
+	context := newTestTelemetryJobContext()
+	context.StoreReadTimeout = 10 * time.Millisecond
+	context.teamService = teamService
+	context.endpointService = &fakeEndpointService{endpoints: []portainer.Endpoint{{ID: 1}}}
+
+	done := make(chan struct{})
+	var data *TelemetryData
+	var sectionErrors []*SectionError
+	go func() {
+		data, sectionErrors, _ = computeTelemetry(context, newRetryBudget(0))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the computation to complete despite the blocked read")
+	}
+
+	if len(sectionErrors) != 1 || sectionErrors[0].Section != SectionTeam || sectionErrors[0].Err != errStoreReadTimeout {
+		t.Fatalf("Expected a store read timeout on the team section, but got %v", sectionErrors)
+	}
+	if data.Team.Count != 0 {
+		t.Errorf("Expected the team section to be left empty, but the count was %d", data.Team.Count)
+	}
+	if data.Endpoint.Count != 1 {
+		t.Errorf("Expected the endpoint section to be computed, but the count was %d", data.Endpoint.Count)
+	}
+}
+
+func TestStoreReadTimeoutLateResult(t *testing.T) {
+	teamService := &blockedTeamService{release: make(chan struct{})}
+
+	context := newTestTelemetryJobContext()
+	context.StoreReadTimeout = 10 * time.Millisecond
+	context.teamService = teamService
+
+	teams, err := context.teams()
+	if err != errStoreReadTimeout {
+		t.Fatalf("Expected a store read timeout, but got %v", err)
+	}
+
+	// The read completes after the timeout, its result must not reach the caller
+	close(teamService.release)
+	time.Sleep(20 * time.Millisecond)
+
+	if teams != nil {
+		t.Errorf("Expected no team to be returned after the timeout, but got %v", teams)
+	}
+}
@@ -0,0 +1,194 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeFromStore(t *testing.T) {
+	store := &fakeTelemetryDataStore{
+		endpoints:              []portainer.Endpoint{{ID: 1, Type: portainer.DockerEnvironment}, {ID: 2, Type: portainer.EdgeAgentEnvironment}},
+		stacks:                 []portainer.Stack{{ID: 1}},
+		teams:                  []portainer.Team{{ID: 1}},
+		telemetryConfiguration: &portainer.TelemetryConfiguration{TelemetryID: "backup-id"},
+	}
+
+	data, err := ComputeFromStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.TelemetryID != "backup-id" {
+		t.Errorf("Expected the telemetry identifier of the store to be used, but got %s", data.TelemetryID)
+	}
+	if data.Endpoint.Count != 2 || data.Endpoint.EdgeCount != 1 {
+		t.Errorf("Expected 2 endpoints including 1 Edge endpoint, but got %d and %d", data.Endpoint.Count, data.Endpoint.EdgeCount)
+	}
+	if data.Stack.Count != 1 || data.Team.Count != 1 {
+		t.Errorf("Expected 1 stack and 1 team, but got %d and %d", data.Stack.Count, data.Team.Count)
+	}
+}
+
+// newBenchmarkTelemetryDataStore returns a store populated with the given number of endpoints,
+// each one with a snapshot of 10 containers, and resource controls, each one granting access to a user.
+func newBenchmarkTelemetryDataStore(endpointCount, resourceControlCount int) *fakeTelemetryDataStore {
+	containers := make([]map[string]interface{}, 10)
+	for i := range containers {
+		containers[i] = map[string]interface{}{
+			"HostConfig": map[string]interface{}{"NanoCpus": 500000000, "Memory": 268435456},
+		}
+	}
+
+	store := &fakeTelemetryDataStore{}
+
+	for i := 0; i < endpointCount; i++ {
+		store.endpoints = append(store.endpoints, portainer.Endpoint{
+			ID:     portainer.EndpointID(i + 1),
+			Type:   portainer.EndpointType(i%4 + 1),
+			URL:    fmt.Sprintf("tcp://10.0.%d.%d:2375", i/256, i%256),
+			Status: portainer.EndpointStatusUp,
+			Snapshots: []portainer.Snapshot{{
+				Time:        time.Now().Unix(),
+				TotalCPU:    4,
+				TotalMemory: 8589934592,
+				SnapshotRaw: portainer.SnapshotRaw{Containers: containers},
+			}},
+		})
+	}
+
+	for i := 0; i < resourceControlCount; i++ {
+		store.resourceControls = append(store.resourceControls, portainer.ResourceControl{
+			ID:           portainer.ResourceControlID(i + 1),
+			ResourceID:   fmt.Sprintf("resource-%d", i),
+			Type:         portainer.ContainerResourceControl,
+			UserAccesses: []portainer.UserResourceAccess{{UserID: portainer.UserID(i%100 + 1)}},
+		})
+	}
+
+	for i := 0; i < 100; i++ {
+		store.users = append(store.users, portainer.User{ID: portainer.UserID(i + 1), Username: fmt.Sprintf("user-%d", i)})
+	}
+
+	return store
+}
+
+func BenchmarkComputeTelemetry(b *testing.B) {
+	scales := []struct {
+		endpoints        int
+		resourceControls int
+	}{
+		{endpoints: 10, resourceControls: 100},
+		{endpoints: 100, resourceControls: 1000},
+		{endpoints: 1000, resourceControls: 10000},
+	}
+
+	for _, scale := range scales {
+		store := newBenchmarkTelemetryDataStore(scale.endpoints, scale.resourceControls)
+
+		b.Run(fmt.Sprintf("endpoints=%d/resource_controls=%d", scale.endpoints, scale.resourceControls), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, err := ComputeFromStore(store)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeSection(t *testing.T) {
+	store := &fakeTelemetryDataStore{
+		endpoints: []portainer.Endpoint{{ID: 1, Type: portainer.DockerEnvironment}},
+		teams:     []portainer.Team{{ID: 1}, {ID: 2}},
+	}
+
+	data := &TelemetryData{
+		TelemetryID: "existing-id",
+		Endpoint:    EndpointTelemetryData{Count: 5},
+	}
+
+	err := ComputeSection(context.Background(), SectionTeam, data, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Team.Count != 2 {
+		t.Errorf("Expected the team section to be computed, but got %d teams", data.Team.Count)
+	}
+	if data.TelemetryID != "existing-id" || data.Endpoint.Count != 5 {
+		t.Errorf("Expected the other sections to be left untouched, but got %s and %d endpoints", data.TelemetryID, data.Endpoint.Count)
+	}
+
+	err = ComputeSection(context.Background(), "tag", data, store)
+	sectionError, ok := err.(*SectionError)
+	if !ok || sectionError.Err != errUnknownSection {
+		t.Errorf("Expected an unknown section error, but got %v", err)
+	}
+}
+
+func TestComputeFromStoreSectionError(t *testing.T) {
+	storeErr := errors.New("stack bucket is corrupted")
+	store := &fakeTelemetryDataStore{
+		stacks:    []portainer.Stack{{ID: 1}},
+		stacksErr: storeErr,
+	}
+
+	_, err := ComputeFromStore(store)
+	sectionError, ok := err.(*SectionError)
+	if !ok {
+		t.Fatalf("Expected a section error, but got %v", err)
+	}
+	if sectionError.Section != SectionStack || sectionError.Err != storeErr {
+		t.Errorf("Expected the error of the store to be reported for the stack section, but got %s: %v", sectionError.Section, sectionError.Err)
+	}
+
+	data := &TelemetryData{}
+	err = ComputeSection(context.Background(), SectionTeam, data, store)
+	if err != nil {
+		t.Errorf("Expected the team section to be computed despite the stack error, but got %v", err)
+	}
+}
+
+func TestComputeSectionStoreErrors(t *testing.T) {
+	storeErr := errors.New("bucket is corrupted")
+
+	cases := []struct {
+		section string
+		store   *fakeTelemetryDataStore
+	}{
+		{SectionEndpoint, &fakeTelemetryDataStore{endpointsErr: storeErr}},
+		{SectionEdgeCompute, &fakeTelemetryDataStore{schedulesErr: storeErr}},
+		{SectionRegistry, &fakeTelemetryDataStore{registriesErr: storeErr}},
+		{SectionResourceControl, &fakeTelemetryDataStore{resourceControlsErr: storeErr}},
+		{SectionSettings, &fakeTelemetryDataStore{settingsErr: storeErr}},
+		{SectionStack, &fakeTelemetryDataStore{stacksErr: storeErr}},
+		{SectionTeam, &fakeTelemetryDataStore{teamsErr: storeErr}},
+		{SectionTeam, &fakeTelemetryDataStore{teamMembershipsErr: storeErr}},
+		{SectionUser, &fakeTelemetryDataStore{usersErr: storeErr}},
+		{SectionWebhook, &fakeTelemetryDataStore{webhooksErr: storeErr}},
+	}
+
+	for _, c := range cases {
+		err := ComputeSection(context.Background(), c.section, &TelemetryData{}, c.store)
+		sectionError, ok := err.(*SectionError)
+		if !ok {
+			t.Errorf("Expected a section error for the %s section, but got %v", c.section, err)
+			continue
+		}
+		if sectionError.Section != c.section || sectionError.Err != storeErr {
+			t.Errorf("Expected the error of the store to be reported for the %s section, but got %s: %v", c.section, sectionError.Section, sectionError.Err)
+		}
+	}
+
+	_, err := ComputeFromStore(&fakeTelemetryDataStore{telemetryConfigurationErr: storeErr})
+	sectionError, ok := err.(*SectionError)
+	if !ok || sectionError.Section != SectionIdentifier || sectionError.Err != storeErr {
+		t.Errorf("Expected the error of the store to be reported for the identifier section, but got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"math/rand"
+	"runtime"
+
+	"github.com/portainer/portainer/api"
+)
+
+// SyntheticTelemetryID is the identifier of the synthetic telemetry data
+const SyntheticTelemetryID = "synthetic"
+
+// SyntheticSpec describes the size of the fleet used to generate synthetic telemetry data
+type SyntheticSpec struct {
+	Endpoints        int
+	Registries       int
+	ResourceControls int
+	Stacks           int
+	Teams            int
+	Users            int
+	Webhooks         int
+
+	// Seed makes the generation deterministic, the same spec always generates the same data
+	Seed int64
+}
+
+// GenerateSyntheticTelemetry fabricates the telemetry data of a fleet of the size described by spec,
+// e.g. to load test the telemetry server or to size MaxPayloadBytes. Every endpoint gets an entry,
+// the types, cloud providers, Docker editions and authentication methods are drawn at random.
+// The data is not computed from any store and is never sent by the telemetry job.
+func GenerateSyntheticTelemetry(spec SyntheticSpec) *TelemetryData {
+	random := rand.New(rand.NewSource(spec.Seed))
+
+	data := &TelemetryData{
+		TelemetryID:      SyntheticTelemetryID,
+		IdentifierSource: IdentifierSourceGenerated,
+		Runtime: RuntimeTelemetryData{
+			Version:  portainer.APIVersion,
+			Platform: runtime.GOOS,
+			Arch:     runtime.GOARCH,
+		},
+	}
+
+	endpointTypes := []string{EndpointTypeDocker, EndpointTypeAgent, EndpointTypeAzure, EndpointTypeEdge}
+	cloudProviders := []string{CloudProviderAWS, CloudProviderAzure, CloudProviderGCP, CloudProviderOnPrem, CloudProviderUnknown}
+	dockerEditions := []string{DockerEditionCE, DockerEditionEE, DockerEditionMirantis, DockerEditionUnknown}
+
+	data.Endpoint.Count = spec.Endpoints
+	data.Endpoint.CloudProviderDistribution = make(map[string]int)
+	data.Endpoint.DockerEditionDistribution = make(map[string]int)
+	data.Endpoint.Endpoints = make([]EndpointEnvironmentTelemetryData, 0, spec.Endpoints)
+
+	for i := 0; i < spec.Endpoints; i++ {
+		environment := EndpointEnvironmentTelemetryData{
+			Type:           endpointTypes[random.Intn(len(endpointTypes))],
+			CloudProvider:  cloudProviders[random.Intn(len(cloudProviders))],
+			NetworkDrivers: []NetworkDriverTelemetryData{{Driver: "bridge", Count: 1 + random.Intn(5)}},
+		}
+
+		switch environment.Type {
+		case EndpointTypeDocker:
+			data.Endpoint.DockerCount++
+		case EndpointTypeAgent:
+			data.Endpoint.AgentCount++
+		case EndpointTypeAzure:
+			data.Endpoint.AzureCount++
+		case EndpointTypeEdge:
+			data.Endpoint.EdgeCount++
+		}
+
+		if environment.Type != EndpointTypeAzure {
+			environment.DockerEdition = dockerEditions[random.Intn(len(dockerEditions))]
+			data.Endpoint.DockerEditionDistribution[environment.DockerEdition]++
+		}
+
+		data.Endpoint.CloudProviderDistribution[environment.CloudProvider]++
+		data.Endpoint.Endpoints = append(data.Endpoint.Endpoints, environment)
+	}
+
+	data.Endpoint.SampledCount = len(data.Endpoint.Endpoints)
+	data.Endpoint.SampleRate = 1
+	data.Endpoint.AgentPercent = percentage(data.Endpoint.AgentCount, data.Endpoint.Count)
+	data.Endpoint.EdgePercent = percentage(data.Endpoint.EdgeCount, data.Endpoint.Count)
+	data.Endpoint.DirectPercent = percentage(data.Endpoint.DockerCount, data.Endpoint.Count)
+
+	registryTypes := []portainer.RegistryType{portainer.QuayRegistry, portainer.AzureRegistry, portainer.CustomRegistry, portainer.GitlabRegistry}
+
+	data.Registry.Count = spec.Registries
+	data.Registry.Configurations = make([]RegistryConfigurationTelemetryData, 0, spec.Registries)
+	for i := 0; i < spec.Registries; i++ {
+		data.Registry.Configurations = append(data.Registry.Configurations, RegistryConfigurationTelemetryData{
+			Type:           RegistryTypeTelemetry(registryTypes[random.Intn(len(registryTypes))]),
+			Authentication: random.Intn(2) == 0,
+			Options:        map[string]bool{},
+		})
+	}
+
+	authMethods := []string{AuthMethodInternal, AuthMethodLDAP, AuthMethodOAuth}
+
+	data.User.Count = spec.Users
+	data.User.AuthMethodDistribution = make(map[string]int)
+	for i := 0; i < spec.Users; i++ {
+		data.User.AuthMethodDistribution[authMethods[random.Intn(len(authMethods))]]++
+	}
+
+	data.Team.Count = spec.Teams
+	if spec.Teams > 0 {
+		data.Team.AverageTeamSize = float64(spec.Users) / float64(spec.Teams)
+		data.Team.MaxTeamSize = spec.Users
+	}
+
+	data.ResourceControl.Count = spec.ResourceControls
+	data.Stack.Count = spec.Stacks
+	data.Webhook.Count = spec.Webhooks
+	data.Webhook.TriggeredSinceLastRun = webhookTriggersNotTracked
+
+	return data
+}
@@ -0,0 +1,52 @@
+package telemetry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateSyntheticTelemetry(t *testing.T) {
+	spec := SyntheticSpec{
+		Endpoints:        10000,
+		Registries:       50,
+		ResourceControls: 2000,
+		Stacks:           300,
+		Teams:            20,
+		Users:            400,
+		Webhooks:         10,
+		Seed:             42,
+	}
+
+	data := GenerateSyntheticTelemetry(spec)
+
+	if data.Endpoint.Count != spec.Endpoints || len(data.Endpoint.Endpoints) != spec.Endpoints {
+		t.Errorf("Expected %d endpoints, but got a count of %d and %d entries", spec.Endpoints, data.Endpoint.Count, len(data.Endpoint.Endpoints))
+	}
+
+	typeCount := data.Endpoint.DockerCount + data.Endpoint.AgentCount + data.Endpoint.AzureCount + data.Endpoint.EdgeCount
+	if typeCount != spec.Endpoints {
+		t.Errorf("Expected the endpoint types to add up to %d, but got %d", spec.Endpoints, typeCount)
+	}
+
+	providerCount := 0
+	for _, count := range data.Endpoint.CloudProviderDistribution {
+		providerCount += count
+	}
+	if providerCount != spec.Endpoints {
+		t.Errorf("Expected the cloud provider distribution to add up to %d, but got %d", spec.Endpoints, providerCount)
+	}
+
+	if data.Registry.Count != spec.Registries || len(data.Registry.Configurations) != spec.Registries {
+		t.Errorf("Expected %d registries, but got a count of %d and %d configurations", spec.Registries, data.Registry.Count, len(data.Registry.Configurations))
+	}
+	if data.User.Count != spec.Users || data.Team.Count != spec.Teams || data.Stack.Count != spec.Stacks {
+		t.Errorf("Expected %d users, %d teams and %d stacks, but got %d, %d and %d", spec.Users, spec.Teams, spec.Stacks, data.User.Count, data.Team.Count, data.Stack.Count)
+	}
+	if data.ResourceControl.Count != spec.ResourceControls || data.Webhook.Count != spec.Webhooks {
+		t.Errorf("Expected %d resource controls and %d webhooks, but got %d and %d", spec.ResourceControls, spec.Webhooks, data.ResourceControl.Count, data.Webhook.Count)
+	}
+
+	if !reflect.DeepEqual(data, GenerateSyntheticTelemetry(spec)) {
+		t.Errorf("Expected the same spec to generate the same data")
+	}
+}
@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"github.com/portainer/portainer/api"
+)
+
+func computeTeamTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	teams, err := context.teams()
+	if err != nil {
+		return err
+	}
+
+	memberships, err := context.teamMemberships()
+	if err != nil {
+		return err
+	}
+
+	teamSizes := make(map[portainer.TeamID]int)
+	for _, membership := range memberships {
+		teamSizes[membership.TeamID]++
+	}
+
+	data.Team.Count = len(teams)
+
+	totalMembers := 0
+	for _, team := range teams {
+		size := teamSizes[team.ID]
+		totalMembers += size
+
+		if size > data.Team.MaxTeamSize {
+			data.Team.MaxTeamSize = size
+		}
+	}
+
+	if len(teams) > 0 {
+		data.Team.AverageTeamSize = float64(totalMembers) / float64(len(teams))
+	}
+
+	return nil
+}
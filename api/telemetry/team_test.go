@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeTeamTelemetry(t *testing.T) {
+	t.Run("No team", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+
+		data := &TelemetryData{}
+		err := computeTeamTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Team.AverageTeamSize != 0 || data.Team.MaxTeamSize != 0 {
+			t.Errorf("Expected team sizes to be 0, but got average %f and max %d", data.Team.AverageTeamSize, data.Team.MaxTeamSize)
+		}
+	})
+
+	t.Run("Teams of varying sizes", func(t *testing.T) {
+		context := newTestTelemetryJobContext()
+		context.teamService = &fakeTeamService{teams: []portainer.Team{
+			{ID: 1, Name: "empty"},
+			{ID: 2, Name: "small"},
+			{ID: 3, Name: "large"},
+		}}
+		context.teamMembershipService = &fakeTeamMembershipService{memberships: []portainer.TeamMembership{
+			{ID: 1, UserID: 1, TeamID: 2},
+			{ID: 2, UserID: 1, TeamID: 3},
+			{ID: 3, UserID: 2, TeamID: 3},
+			{ID: 4, UserID: 3, TeamID: 3},
+			{ID: 5, UserID: 4, TeamID: 3},
+			{ID: 6, UserID: 5, TeamID: 3},
+		}}
+
+		data := &TelemetryData{}
+		err := computeTeamTelemetry(context, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.Team.Count != 3 {
+			t.Errorf("Expected Count to be 3, but it was %d instead", data.Team.Count)
+		}
+		if data.Team.AverageTeamSize != 2 {
+			t.Errorf("Expected AverageTeamSize to be 2, but it was %f instead", data.Team.AverageTeamSize)
+		}
+		if data.Team.MaxTeamSize != 5 {
+			t.Errorf("Expected MaxTeamSize to be 5, but it was %d instead", data.Team.MaxTeamSize)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package telemetry
+
+type (
+	// TelemetryData represents the anonymous usage data computed by the telemetry job
+	TelemetryData struct {
+		TelemetryID      string                       `json:"TelemetryID" protobuf:"1"`
+		IdentifierSource string                       `json:"IdentifierSource" protobuf:"2"`
+		EdgeCompute      EdgeComputeTelemetryData     `json:"EdgeCompute" protobuf:"3"`
+		Endpoint         EndpointTelemetryData        `json:"Endpoint" protobuf:"4"`
+		Registry         RegistryTelemetryData        `json:"Registry" protobuf:"5"`
+		ResourceControl  ResourceControlTelemetryData `json:"ResourceControl" protobuf:"12"`
+		Runtime          RuntimeTelemetryData         `json:"Runtime" protobuf:"6"`
+		Settings         SettingsTelemetryData        `json:"Settings" protobuf:"7"`
+		Stack            StackTelemetryData           `json:"Stack" protobuf:"8"`
+		Team             TeamTelemetryData            `json:"Team" protobuf:"9"`
+		User             UserTelemetryData            `json:"User" protobuf:"10"`
+		Webhook          WebhookTelemetryData         `json:"Webhook" protobuf:"11"`
+		DeploymentLabel  string                       `json:"DeploymentLabel,omitempty" telemetry:"since=2" protobuf:"13"`
+		Security         SecurityTelemetryData        `json:"Security" telemetry:"since=2" protobuf:"14"`
+		Process          ProcessTelemetryData         `json:"Process" telemetry:"since=2" protobuf:"15"`
+		WindowSize       int                          `json:"WindowSize,omitempty" telemetry:"since=2" merge:"max" protobuf:"16"`
+		SheddedSections  []string                     `json:"SheddedSections" telemetry:"since=2" merge:"set" protobuf:"17"`
+	}
+
+	// EdgeComputeTelemetryData represents the telemetry data associated to the Edge compute features.
+	// Protobuf field 5 is reserved, it was used by the removed CheckinIntervalDistribution field.
+	EdgeComputeTelemetryData struct {
+		ConnectedEdgeAgents           int                              `json:"ConnectedEdgeAgents" protobuf:"1"`
+		EdgePortainerURLConfigured    bool                             `json:"EdgePortainerURLConfigured" protobuf:"2"`
+		TunnelServerAddressConfigured bool                             `json:"TunnelServerAddressConfigured" protobuf:"3"`
+		Schedule                      EdgeComputeScheduleTelemetryData `json:"Schedule" telemetry:"since=2" protobuf:"4"`
+		ActiveTunnels                 int                              `json:"ActiveTunnels" telemetry:"since=2" protobuf:"6"`
+		EdgeCheckinInterval           int                              `json:"EdgeCheckinInterval" telemetry:"since=2" merge:"max" protobuf:"7"`
+	}
+
+	// EdgeComputeScheduleTelemetryData represents the telemetry data associated to the schedules
+	EdgeComputeScheduleTelemetryData struct {
+		JobTypeCounts map[string]int `json:"JobTypeCounts" protobuf:"1"`
+	}
+
+	// EndpointTelemetryData represents the telemetry data associated to the endpoints.
+	// Protobuf field 12 is reserved, it was used by the removed OvercommittedEndpoints field.
+	EndpointTelemetryData struct {
+		Count                       int                                `json:"Count" protobuf:"1"`
+		DockerCount                 int                                `json:"DockerCount" protobuf:"2"`
+		AgentCount                  int                                `json:"AgentCount" protobuf:"3"`
+		AzureCount                  int                                `json:"AzureCount" protobuf:"4"`
+		EdgeCount                   int                                `json:"EdgeCount" protobuf:"5"`
+		AgentPercent                float64                            `json:"AgentPercent" protobuf:"15"`
+		EdgePercent                 float64                            `json:"EdgePercent" protobuf:"16"`
+		DirectPercent               float64                            `json:"DirectPercent" protobuf:"17"`
+		UnreachableCount            int                                `json:"UnreachableCount" protobuf:"6"`
+		SnapshotErrorCount          int                                `json:"SnapshotErrorCount" protobuf:"7"`
+		PendingEdgeCount            int                                `json:"PendingEdgeCount" protobuf:"8"`
+		NonDefaultPortCount         int                                `json:"NonDefaultPortCount" protobuf:"9"`
+		SocketCount                 int                                `json:"SocketCount" protobuf:"10"`
+		SnapshottedInLastInterval   int                                `json:"SnapshottedInLastInterval" protobuf:"11"`
+		CloudProviderDistribution   map[string]int                     `json:"CloudProviderDistribution" protobuf:"13"`
+		Endpoints                   []EndpointEnvironmentTelemetryData `json:"Endpoints" protobuf:"14"`
+		SampledCount                int                                `json:"SampledCount" protobuf:"18"`
+		SampleRate                  float64                            `json:"SampleRate" protobuf:"19"`
+		TLSSkipVerifyCount          int                                `json:"TLSSkipVerifyCount" telemetry:"since=2" protobuf:"20"`
+		DockerEditionDistribution   map[string]int                     `json:"DockerEditionDistribution" telemetry:"since=2" protobuf:"21"`
+		VulnerableImages            int                                `json:"VulnerableImages" telemetry:"since=2" protobuf:"22"`
+		CriticalVulnerabilities     int                                `json:"CriticalVulnerabilities" telemetry:"since=2" protobuf:"23"`
+		PubliclyExposedCount        int                                `json:"PubliclyExposedCount" telemetry:"since=2" protobuf:"24"`
+		ExposureUnknownCount        int                                `json:"ExposureUnknownCount" telemetry:"since=2" protobuf:"25"`
+		CorruptSnapshotCount        int                                `json:"CorruptSnapshotCount" telemetry:"since=2" protobuf:"26"`
+		AverageSnapshotSuccessRate  float64                            `json:"AverageSnapshotSuccessRate" telemetry:"since=2" protobuf:"27"`
+		CreatedViaAPI               int                                `json:"CreatedViaAPI" telemetry:"since=2" protobuf:"28"`
+		CreatedViaUI                int                                `json:"CreatedViaUI" telemetry:"since=2" protobuf:"29"`
+		CreatedViaUnknown           int                                `json:"CreatedViaUnknown" telemetry:"since=2" protobuf:"30"`
+		OutdatedAgentCount          int                                `json:"OutdatedAgentCount" telemetry:"since=2" protobuf:"31"`
+		UnknownAgentVersionCount    int                                `json:"UnknownAgentVersionCount" telemetry:"since=2" protobuf:"32"`
+		EndpointsWithRegistryMirror int                                `json:"EndpointsWithRegistryMirror" telemetry:"since=2" protobuf:"33"`
+	}
+
+	// EndpointEnvironmentTelemetryData represents the telemetry data associated to a single endpoint
+	EndpointEnvironmentTelemetryData struct {
+		Type                string                       `json:"Type" protobuf:"1"`
+		CloudProvider       string                       `json:"CloudProvider" protobuf:"2"`
+		DockerEdition       string                       `json:"DockerEdition,omitempty" telemetry:"since=2" protobuf:"3"`
+		NetworkDrivers      []NetworkDriverTelemetryData `json:"NetworkDrivers" telemetry:"since=2" protobuf:"4"`
+		SnapshotSuccessRate float64                      `json:"SnapshotSuccessRate" telemetry:"since=2" protobuf:"5"`
+	}
+
+	// NetworkDriverTelemetryData represents the number of networks using a driver on an endpoint
+	NetworkDriverTelemetryData struct {
+		Driver string `json:"Driver" protobuf:"1"`
+		Count  int    `json:"Count" protobuf:"2"`
+	}
+
+	// ProcessTelemetryData represents the telemetry data associated to the Portainer process
+	ProcessTelemetryData struct {
+		HeapAllocBytes uint64 `json:"HeapAllocBytes" protobuf:"1"`
+		NumGoroutine   int    `json:"NumGoroutine" protobuf:"2"`
+		NumGC          uint32 `json:"NumGC" protobuf:"3"`
+		Uptime         int64  `json:"Uptime" merge:"max" protobuf:"4"`
+	}
+
+	// RegistryTelemetryData represents the telemetry data associated to the registries
+	RegistryTelemetryData struct {
+		Count             int                                  `json:"Count" protobuf:"1"`
+		Configurations    []RegistryConfigurationTelemetryData `json:"Configurations" protobuf:"2"`
+		StacksPerRegistry map[string]int                       `json:"StacksPerRegistry" protobuf:"3"`
+		ReachableCount    int                                  `json:"ReachableCount" protobuf:"4"`
+		UnreachableCount  int                                  `json:"UnreachableCount" protobuf:"5"`
+		UnusedCount       int                                  `json:"UnusedCount" telemetry:"since=2" protobuf:"6"`
+	}
+
+	// RegistryConfigurationTelemetryData represents the telemetry data associated to a registry
+	RegistryConfigurationTelemetryData struct {
+		Type           string          `json:"Type" protobuf:"1"`
+		Authentication bool            `json:"Authentication" protobuf:"2"`
+		Options        map[string]bool `json:"Options" protobuf:"3"`
+		URLHash        string          `json:"URLHash,omitempty" telemetry:"since=2" protobuf:"4"`
+	}
+
+	// ResourceControlTelemetryData represents the telemetry data associated to the resource controls
+	ResourceControlTelemetryData struct {
+		Count              int                `json:"Count" protobuf:"1"`
+		DanglingUserGrants int                `json:"DanglingUserGrants" protobuf:"2"`
+		PerTypeCoverage    map[string]float64 `json:"PerTypeCoverage" telemetry:"since=2" protobuf:"3"`
+	}
+
+	// RuntimeTelemetryData represents the telemetry data associated to the Portainer runtime
+	RuntimeTelemetryData struct {
+		Version  string `json:"Version" protobuf:"1"`
+		Platform string `json:"Platform" protobuf:"2"`
+		Arch     string `json:"Arch" protobuf:"3"`
+	}
+
+	// SecurityTelemetryData represents the telemetry data associated to the authentication security
+	SecurityTelemetryData struct {
+		AuthFailuresSinceLastRun int `json:"AuthFailuresSinceLastRun" protobuf:"1"`
+		LockedAccounts           int `json:"LockedAccounts" protobuf:"2"`
+	}
+
+	// SettingsTelemetryData represents the telemetry data associated to the application settings
+	SettingsTelemetryData struct {
+		CustomCACertificates        int   `json:"CustomCACertificates" protobuf:"1"`
+		ResourceDetailsExcluded     bool  `json:"ResourceDetailsExcluded" protobuf:"2"`
+		PublicAccessEnabled         bool  `json:"PublicAccessEnabled" protobuf:"3"`
+		HostManagementUsedEndpoints int   `json:"HostManagementUsedEndpoints" telemetry:"since=2" protobuf:"4"`
+		SettingsLastModified        int64 `json:"SettingsLastModified" telemetry:"since=2" merge:"max" protobuf:"5"`
+	}
+
+	// StackTelemetryData represents the telemetry data associated to the stacks
+	StackTelemetryData struct {
+		Count                   int `json:"Count" protobuf:"1"`
+		StacksWithSecrets       int `json:"StacksWithSecrets" protobuf:"2"`
+		StacksWithConfigs       int `json:"StacksWithConfigs" protobuf:"3"`
+		LargeStackCount         int `json:"LargeStackCount" protobuf:"4"`
+		TeamOwnedStacks         int `json:"TeamOwnedStacks" protobuf:"5"`
+		UserOwnedStacks         int `json:"UserOwnedStacks" protobuf:"6"`
+		PublicStacks            int `json:"PublicStacks" protobuf:"7"`
+		BrokenFileStacks        int `json:"BrokenFileStacks" telemetry:"since=2" protobuf:"8"`
+		StacksCreatedLast7Days  int `json:"StacksCreatedLast7Days" telemetry:"since=2" protobuf:"9"`
+		StacksCreatedLast30Days int `json:"StacksCreatedLast30Days" telemetry:"since=2" protobuf:"10"`
+	}
+
+	// TeamTelemetryData represents the telemetry data associated to the teams
+	TeamTelemetryData struct {
+		Count           int     `json:"Count" protobuf:"1"`
+		AverageTeamSize float64 `json:"AverageTeamSize" protobuf:"2"`
+		MaxTeamSize     int     `json:"MaxTeamSize" merge:"max" protobuf:"3"`
+	}
+
+	// UserTelemetryData represents the telemetry data associated to the users
+	UserTelemetryData struct {
+		Count                  int            `json:"Count" protobuf:"1"`
+		AuthMethodDistribution map[string]int `json:"AuthMethodDistribution" protobuf:"2"`
+	}
+
+	// WebhookTelemetryData represents the telemetry data associated to the webhooks
+	WebhookTelemetryData struct {
+		Count                 int `json:"Count" protobuf:"1"`
+		TriggeredSinceLastRun int `json:"TriggeredSinceLastRun" protobuf:"2"`
+	}
+)
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// telemetryDataRoundTrips returns true when the data is identical once encoded as JSON and decoded back
+func telemetryDataRoundTrips(t *testing.T, data *TelemetryData) bool {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		t.Errorf("Unable to marshal telemetry data: %s", err)
+		return false
+	}
+
+	var decoded TelemetryData
+	err = json.Unmarshal(payload, &decoded)
+	if err != nil {
+		t.Errorf("Unable to unmarshal telemetry data: %s", err)
+		return false
+	}
+
+	if !reflect.DeepEqual(*data, decoded) {
+		t.Errorf("Expected the telemetry data to round-trip\npayload: %s", payload)
+		return false
+	}
+	return true
+}
+
+func TestTelemetryDataJSONRoundTripSeeds(t *testing.T) {
+	seeds := map[string]*TelemetryData{
+		"sample":       sampleTelemetryData(),
+		"large counts": {Endpoint: EndpointTelemetryData{Count: math.MaxInt64, SnapshotErrorCount: math.MinInt64}, Process: ProcessTelemetryData{HeapAllocBytes: math.MaxUint64, NumGC: math.MaxUint32}},
+		"unicode":      {TelemetryID: "télémétrie-测试-🚀", DeploymentLabel: "prod-éu ", Runtime: RuntimeTelemetryData{Version: "\x00\t\"\\"}},
+		"empty slices": {Endpoint: EndpointTelemetryData{Endpoints: []EndpointEnvironmentTelemetryData{}, CloudProviderDistribution: map[string]int{}}, Registry: RegistryTelemetryData{Configurations: []RegistryConfigurationTelemetryData{}}},
+		"not tracked":  {Webhook: WebhookTelemetryData{TriggeredSinceLastRun: webhookTriggersNotTracked}, Security: SecurityTelemetryData{AuthFailuresSinceLastRun: securityDataNotTracked}},
+	}
+
+	for name, data := range seeds {
+		t.Run(name, func(t *testing.T) {
+			telemetryDataRoundTrips(t, data)
+		})
+	}
+}
+
+func TestTelemetryDataJSONRoundTripRandom(t *testing.T) {
+	config := &quick.Config{
+		MaxCount: 200,
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+
+	err := quick.Check(func(data TelemetryData) bool {
+		return telemetryDataRoundTrips(t, &data)
+	}, config)
+	if err != nil {
+		t.Error(err)
+	}
+}
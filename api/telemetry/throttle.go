@@ -0,0 +1,34 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// defaultMinSendInterval is the default minimum interval between two telemetry sends
+	defaultMinSendInterval = 24 * time.Hour
+	// defaultStartupGrace is the default duration after startup during which no data is sent
+	defaultStartupGrace = 10 * time.Minute
+)
+
+// sendThrottled returns true when the last successful submission persisted in the configuration
+// happened less than MinSendInterval ago.
+func (context *TelemetryJobContext) sendThrottled(configuration *portainer.TelemetryConfiguration) bool {
+	if context.MinSendInterval <= 0 || configuration == nil || configuration.LastSubmission == 0 {
+		return false
+	}
+
+	lastSubmission := time.Unix(configuration.LastSubmission, 0)
+	return context.now().Sub(lastSubmission) < context.MinSendInterval
+}
+
+// withinStartupGrace returns true when the context was created less than StartupGrace ago
+func (context *TelemetryJobContext) withinStartupGrace() bool {
+	if context.StartupGrace <= 0 {
+		return false
+	}
+
+	return context.now().Sub(context.startTime) < context.StartupGrace
+}
@@ -0,0 +1,105 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestRunWithResultThrottled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1600000000, 0)
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.now = func() time.Time { return now }
+	context.telemetryService = &fakeTelemetryService{configuration: &portainer.TelemetryConfiguration{
+		TelemetryID:    "id",
+		LastSubmission: now.Add(-1 * time.Hour).Unix(),
+	}}
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	if !result.Throttled || result.Sent {
+		t.Errorf("Expected the send to be throttled, but got Throttled=%t and Sent=%t", result.Throttled, result.Sent)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no request to be sent, but %d were sent", requests)
+	}
+
+	now = now.Add(24 * time.Hour)
+	result = runner.RunWithResult()
+
+	if !result.Sent || requests != 1 {
+		t.Errorf("Expected the data to be sent once the interval elapsed, but got Sent=%t with %d requests", result.Sent, requests)
+	}
+}
+
+func TestRunWithResultNotThrottledOnSchedule(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// The run takes some time to complete
+		now = now.Add(5 * time.Minute)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.now = func() time.Time { return now }
+	runner := NewTelemetryJobRunner(nil, context)
+
+	start := now
+	runner.RunWithResult()
+
+	now = start.Add(24 * time.Hour)
+	result := runner.RunWithResult()
+
+	if !result.Sent || requests != 2 {
+		t.Errorf("Expected the next scheduled run to send the data, but got Sent=%t with %d requests", result.Sent, requests)
+	}
+}
+
+func TestRunWithResultWithinStartupGrace(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1600000000, 0)
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+	context.now = func() time.Time { return now }
+	context.startTime = now.Add(-5 * time.Minute)
+	context.StartupGrace = 10 * time.Minute
+	runner := NewTelemetryJobRunner(nil, context)
+
+	result := runner.RunWithResult()
+
+	if !result.WithinStartupGrace || result.Sent || requests != 0 {
+		t.Errorf("Expected the send to be skipped during the startup grace period, but got WithinStartupGrace=%t and Sent=%t with %d requests", result.WithinStartupGrace, result.Sent, requests)
+	}
+
+	now = now.Add(5 * time.Minute)
+	result = runner.RunWithResult()
+
+	if !result.Sent || requests != 1 {
+		t.Errorf("Expected the data to be sent after the startup grace period, but got Sent=%t with %d requests", result.Sent, requests)
+	}
+}
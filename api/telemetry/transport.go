@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	unixSocketScheme = "unix://"
+	// unixSocketRequestURL is the URL of the requests sent over a Unix socket, the host is ignored
+	unixSocketRequestURL = "http://unix/"
+
+	sendIdleConnTimeout = 90 * time.Second
+)
+
+// sendClientCache holds the HTTP clients used to send the telemetry data, one per destination URL.
+// It is shared by the copies of the context so that the connections are reused across runs.
+type sendClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// get returns the HTTP client and the request URL used to send the telemetry data to telemetryURL.
+// The client is created on the first call for a given URL and reused afterwards.
+func (cache *sendClientCache) get(telemetryURL string) (*http.Client, string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.clients == nil {
+		cache.clients = make(map[string]*http.Client)
+	}
+
+	client, ok := cache.clients[telemetryURL]
+	if !ok {
+		client = newSendClient(telemetryURL)
+		cache.clients[telemetryURL] = client
+	}
+
+	return client, sendRequestURL(telemetryURL)
+}
+
+// newSendClient returns the HTTP client used to send the telemetry data. The client keeps the
+// connections alive between sends, the timeout is set on each request with withSendDeadline.
+// When the telemetry URL uses the unix:// scheme (e.g. unix:///var/run/collector.sock),
+// the client dials the socket and the data is sent over HTTP on that connection.
+func newSendClient(telemetryURL string) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   time.Second * time.Duration(defaultSendTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        2,
+		IdleConnTimeout:     sendIdleConnTimeout,
+		TLSHandshakeTimeout: time.Second * time.Duration(defaultSendTimeout),
+	}
+
+	if strings.HasPrefix(telemetryURL, unixSocketScheme) {
+		socketPath := strings.TrimPrefix(telemetryURL, unixSocketScheme)
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// sendRequestURL returns the URL of the requests sent to telemetryURL
+func sendRequestURL(telemetryURL string) string {
+	if strings.HasPrefix(telemetryURL, unixSocketScheme) {
+		return unixSocketRequestURL
+	}
+	return telemetryURL
+}
+
+// withSendDeadline returns a copy of the request that is canceled after the send timeout
+func withSendDeadline(request *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(request.Context(), time.Second*time.Duration(defaultSendTimeout))
+	return request.WithContext(ctx), cancel
+}
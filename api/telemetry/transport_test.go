@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSendTelemetryOverUnixSocket(t *testing.T) {
+	directory, err := ioutil.TempDir("", "telemetry-socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	socketPath := path.Join(directory, "collector.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan []byte, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusOK)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = "unix://" + socketPath
+
+	err = context.sendTelemetry(map[string]string{"TelemetryID": "id"}, &TelemetrySendResult{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := <-received
+	if !strings.Contains(string(body), `"data":{"TelemetryID":"id"}`) {
+		t.Errorf("Expected the payload to be received on the socket, but got %s", body)
+	}
+}
+
+func TestSendClientReused(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	context := newTestTelemetryJobContext()
+	context.telemetryURL = server.URL
+
+	for i := 0; i < 2; i++ {
+		err := context.sendTelemetry(map[string]string{"TelemetryID": "id"}, &TelemetrySendResult{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(context.sendClients.clients) != 1 {
+		t.Errorf("Expected a single client to be created, but got %d", len(context.sendClients.clients))
+	}
+
+	first, _ := context.sendClients.get(server.URL)
+	second, _ := context.sendClients.get(server.URL)
+	if first != second {
+		t.Errorf("Expected the same client to be returned for the same URL")
+	}
+
+	if count := atomic.LoadInt32(&connections); count != 1 {
+		t.Errorf("Expected the connection to be reused across sends, but %d connections were opened", count)
+	}
+}
@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"github.com/portainer/portainer/api"
+)
+
+const (
+	// AuthMethodInternal represents the users authenticated against the Portainer database
+	AuthMethodInternal = "internal"
+	// AuthMethodLDAP represents the users authenticated against a LDAP server
+	AuthMethodLDAP = "ldap"
+	// AuthMethodOAuth represents the users authenticated against an OAuth authorization server
+	AuthMethodOAuth = "oauth"
+	// AuthMethodUnknown represents the users without password when internal authentication is configured
+	AuthMethodUnknown = "unknown"
+)
+
+func computeUserTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	users, err := context.users()
+	if err != nil {
+		return err
+	}
+
+	settings, err := context.settings()
+	if err != nil {
+		return err
+	}
+
+	data.User.Count = len(users)
+	data.User.AuthMethodDistribution = make(map[string]int)
+
+	for _, user := range users {
+		data.User.AuthMethodDistribution[userAuthMethod(&user, settings.AuthenticationMethod)]++
+	}
+
+	return nil
+}
+
+// userAuthMethod returns the authentication method of a user. The authentication source of a user
+// is not stored: users with a password authenticate against the Portainer database while the users
+// created through LDAP or OAuth have no password and are attributed to the configured method.
+func userAuthMethod(user *portainer.User, authenticationMethod portainer.AuthenticationMethod) string {
+	if user.Password != "" {
+		return AuthMethodInternal
+	}
+
+	switch authenticationMethod {
+	case portainer.AuthenticationLDAP:
+		return AuthMethodLDAP
+	case portainer.AuthenticationOAuth:
+		return AuthMethodOAuth
+	default:
+		return AuthMethodUnknown
+	}
+}
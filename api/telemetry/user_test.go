@@ -0,0 +1,33 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeUserTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.settingsService = &fakeSettingsService{settings: portainer.Settings{AuthenticationMethod: portainer.AuthenticationLDAP}}
+	context.userService = &fakeUserService{users: []portainer.User{
+		{ID: 1, Username: "admin", Password: "hash"},
+		{ID: 2, Username: "ldap-user-1"},
+		{ID: 3, Username: "ldap-user-2"},
+	}}
+
+	data := &TelemetryData{}
+	err := computeUserTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.User.Count != 3 {
+		t.Errorf("Expected 3 users, but got %d", data.User.Count)
+	}
+	if data.User.AuthMethodDistribution[AuthMethodInternal] != 1 {
+		t.Errorf("Expected 1 internal user, but got %d", data.User.AuthMethodDistribution[AuthMethodInternal])
+	}
+	if data.User.AuthMethodDistribution[AuthMethodLDAP] != 2 {
+		t.Errorf("Expected 2 LDAP users, but got %d", data.User.AuthMethodDistribution[AuthMethodLDAP])
+	}
+}
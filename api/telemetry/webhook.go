@@ -0,0 +1,17 @@
+package telemetry
+
+// webhookTriggersNotTracked is reported as the number of webhook triggers, the webhook
+// executions are not recorded and the number of triggers cannot be computed.
+const webhookTriggersNotTracked = -1
+
+func computeWebhookTelemetry(context *TelemetryJobContext, data *TelemetryData) error {
+	webhooks, err := context.webhooks()
+	if err != nil {
+		return err
+	}
+
+	data.Webhook.Count = len(webhooks)
+	data.Webhook.TriggeredSinceLastRun = webhookTriggersNotTracked
+
+	return nil
+}
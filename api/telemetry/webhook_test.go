@@ -0,0 +1,25 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/portainer/portainer/api"
+)
+
+func TestComputeWebhookTelemetry(t *testing.T) {
+	context := newTestTelemetryJobContext()
+	context.webhookService = &fakeWebhookService{webhooks: []portainer.Webhook{{ID: 1}, {ID: 2}}}
+
+	data := &TelemetryData{}
+	err := computeWebhookTelemetry(context, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Webhook.Count != 2 {
+		t.Errorf("Expected 2 webhooks, but got %d", data.Webhook.Count)
+	}
+	if data.Webhook.TriggeredSinceLastRun != webhookTriggersNotTracked {
+		t.Errorf("Expected TriggeredSinceLastRun to be %d, but got %d", webhookTriggersNotTracked, data.Webhook.TriggeredSinceLastRun)
+	}
+}
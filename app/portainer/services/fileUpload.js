@@ -119,6 +119,7 @@ angular.module('portainer.app').factory('FileUploadService', [
           TLSCACertFile: TLSCAFile,
           TLSCertFile: TLSCertFile,
           TLSKeyFile: TLSKeyFile,
+          CreationSource: 2,
         },
         ignoreLoadingBar: true,
       });
@@ -135,6 +136,7 @@ angular.module('portainer.app').factory('FileUploadService', [
           AzureApplicationID: applicationId,
           AzureTenantID: tenantId,
           AzureAuthenticationKey: authenticationKey,
+          CreationSource: 2,
         },
         ignoreLoadingBar: true,
       });